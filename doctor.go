@@ -0,0 +1,23 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/sanity-labs/blueprints-tui/internal/config"
+	"github.com/sanity-labs/blueprints-tui/internal/doctor"
+)
+
+// runDoctor implements `blueprints-tui doctor`. It accepts the same config
+// flags as the TUI so it diagnoses exactly what a launch would use.
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	var flags config.Flags
+	configFlags(fs, &flags)
+	fs.Parse(args)
+
+	if !doctor.Run(os.Stdout, flags) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrUnauthorized is returned for a 401 once re-authentication was abandoned.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Redact returns token with all but its first and last four characters
+// masked, suitable for printing.
+func Redact(token string) string {
+	if len(token) < 12 {
+		return "****"
+	}
+	return token[:4] + "…" + token[len(token)-4:]
+}
+
+// authGate holds the current token and pauses requests after a 401 until
+// the token is replaced. Requests started while paused wait too, so an
+// expired token produces a single prompt rather than a burst of errors.
+type authGate struct {
+	mu       sync.Mutex
+	token    string
+	paused   chan struct{} // non-nil while paused; closed on resume/abandon
+	rejected bool          // set when the last pause was abandoned
+	required chan struct{}
+}
+
+func newAuthGate(token string) *authGate {
+	return &authGate{
+		token:    token,
+		required: make(chan struct{}, 1),
+	}
+}
+
+func (g *authGate) current() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.token
+}
+
+// wait blocks while requests are paused and returns the token to use.
+func (g *authGate) wait() (string, error) {
+	g.mu.Lock()
+	ch := g.paused
+	g.mu.Unlock()
+	if ch != nil {
+		<-ch
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if ch != nil && g.rejected {
+		return "", ErrUnauthorized
+	}
+	return g.token, nil
+}
+
+// pause records that token was rejected and blocks until it is replaced.
+// If the token already changed since the request was sent, it returns
+// immediately so the caller retries with the new one.
+func (g *authGate) pause(token string) error {
+	g.mu.Lock()
+	if g.token != token {
+		g.mu.Unlock()
+		return nil
+	}
+	if g.paused == nil {
+		g.paused = make(chan struct{})
+		g.rejected = false
+		select {
+		case g.required <- struct{}{}:
+		default:
+		}
+	}
+	ch := g.paused
+	g.mu.Unlock()
+
+	<-ch
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.rejected {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+func (g *authGate) resume(token string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.token = token
+	g.rejected = false
+	if g.paused != nil {
+		close(g.paused)
+		g.paused = nil
+	}
+}
+
+func (g *authGate) abandon() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.rejected = true
+	if g.paused != nil {
+		close(g.paused)
+		g.paused = nil
+	}
+}
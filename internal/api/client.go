@@ -2,22 +2,27 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"time"
 )
 
 type Client struct {
 	apiURL    string
 	baseURL   string
-	token     string
+	auth      *authGate
 	scopeType string
 	scopeID   string
 	debugLog  *log.Logger
 	http      *http.Client
+	// noReauth fails requests on a 401 instead of pausing them, for
+	// clients nobody answers AuthRequired for.
+	noReauth bool
 }
 
 func (c *Client) Debugf(format string, args ...interface{}) {
@@ -30,7 +35,7 @@ func NewClient(apiURL, token, scopeType, scopeID string, debug bool) *Client {
 	c := &Client{
 		apiURL:    apiURL,
 		baseURL:   apiURL + "/vX/blueprints",
-		token:     token,
+		auth:      newAuthGate(token),
 		scopeType: scopeType,
 		scopeID:   scopeID,
 		http:      &http.Client{},
@@ -44,9 +49,68 @@ func NewClient(apiURL, token, scopeType, scopeID string, debug bool) *Client {
 	return c
 }
 
-func (c *Client) SetScope(scopeType, scopeID string) {
-	c.scopeType = scopeType
-	c.scopeID = scopeID
+// APIURL returns the API base URL the client was created with.
+func (c *Client) APIURL() string {
+	return c.apiURL
+}
+
+// Token returns the token requests are currently sent with.
+func (c *Client) Token() string {
+	return c.auth.current()
+}
+
+// SetToken replaces the token and resumes any requests paused on a 401.
+func (c *Client) SetToken(token string) {
+	c.auth.resume(token)
+}
+
+// AuthRequired returns a channel that receives once each time a request
+// hits a 401 and requests are paused waiting for a new token. Answer with
+// SetToken to retry them, or AbandonAuth to fail them.
+func (c *Client) AuthRequired() <-chan struct{} {
+	return c.auth.required
+}
+
+// AbandonAuth fails every request paused on a 401 with ErrUnauthorized.
+func (c *Client) AbandonAuth() {
+	c.auth.abandon()
+}
+
+// DisableReauth makes a 401 fail the request with ErrUnauthorized instead
+// of pausing it until SetToken or AbandonAuth is called.
+func (c *Client) DisableReauth() {
+	c.noReauth = true
+}
+
+// Scope returns the scope type and ID requests are sent with.
+func (c *Client) Scope() (scopeType, scopeID string) {
+	return c.scopeType, c.scopeID
+}
+
+// WithScope returns a copy of c that sends requests in another scope. The
+// copy shares the token, so one re-authentication resumes both.
+func (c *Client) WithScope(scopeType, scopeID string) *Client {
+	cc := *c
+	cc.scopeType = scopeType
+	cc.scopeID = scopeID
+	return &cc
+}
+
+// BaseURL returns the Blueprints API root, e.g. https://api.sanity.io/vX/blueprints.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// SetTimeout bounds every request. Zero means no timeout.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.http.Timeout = d
+}
+
+// Whoami returns the user the token belongs to.
+func (c *Client) Whoami() (User, error) {
+	var u User
+	err := c.getManagement("/v2021-06-07/users/me", &u)
+	return u, err
 }
 
 func (c *Client) ListOrganizations() ([]Organization, error) {
@@ -157,89 +221,116 @@ func (c *Client) get(path string, params url.Values, out any) error {
 		u += "?" + params.Encode()
 	}
 
-	req, err := http.NewRequest("GET", u, nil)
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+c.token)
-	req.Header.Set("x-sanity-scope-type", c.scopeType)
-	req.Header.Set("x-sanity-scope-id", c.scopeID)
+	return c.do(func(token string) (*http.Request, error) {
+		req, err := http.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("x-sanity-scope-type", c.scopeType)
+		req.Header.Set("x-sanity-scope-id", c.scopeID)
+
+		if c.debugLog != nil {
+			c.debugLog.Printf("%s %s", req.Method, req.URL)
+			c.debugLog.Printf("Authorization: Bearer %s", Redact(token))
+			c.debugLog.Printf("x-sanity-scope-type: %s", c.scopeType)
+			c.debugLog.Printf("x-sanity-scope-id: %s", c.scopeID)
+		}
+		return req, nil
+	}, out, true)
+}
 
-	if c.debugLog != nil {
-		c.debugLog.Printf("%s %s", req.Method, req.URL)
-		c.debugLog.Printf("Authorization: Bearer %s...%s", c.token[:4], c.token[len(c.token)-4:])
-		c.debugLog.Printf("x-sanity-scope-type: %s", c.scopeType)
-		c.debugLog.Printf("x-sanity-scope-id: %s", c.scopeID)
-	}
+func (c *Client) getManagement(path string, out any) error {
+	u := c.apiURL + path
 
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
+	return c.do(func(token string) (*http.Request, error) {
+		req, err := http.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("reading response: %w", err)
-	}
+		if c.debugLog != nil {
+			c.debugLog.Printf("%s %s", req.Method, req.URL)
+		}
+		return req, nil
+	}, out, false)
+}
 
-	if c.debugLog != nil {
-		c.debugLog.Printf("Response: %d (%d bytes)", resp.StatusCode, len(body))
-		c.debugLog.Printf("Body: %s", string(body))
-	}
+// do sends the request built by newReq and decodes a 200 response into out.
+// On a 401 it pauses until the token is replaced (see AuthRequired) and
+// then rebuilds and retries the request with the new token.
+func (c *Client) do(newReq func(token string) (*http.Request, error), out any, logBody bool) error {
+	for {
+		token, err := c.auth.wait()
+		if err != nil {
+			return err
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		var apiErr APIError
-		if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
-			return fmt.Errorf("API error %d: %s", resp.StatusCode, apiErr.Message)
+		req, err := newReq(token)
+		if err != nil {
+			return fmt.Errorf("creating request: %w", err)
 		}
-		return fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
-	}
 
-	if err := json.Unmarshal(body, out); err != nil {
-		return fmt.Errorf("decoding response: %w", err)
-	}
-	return nil
-}
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return fmt.Errorf("request failed: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("reading response: %w", err)
+		}
 
-func (c *Client) getManagement(path string, out any) error {
-	u := c.apiURL + path
+		if c.debugLog != nil {
+			c.debugLog.Printf("Response: %d (%d bytes)", resp.StatusCode, len(body))
+			if logBody {
+				c.debugLog.Printf("Body: %s", string(body))
+			}
+		}
 
-	req, err := http.NewRequest("GET", u, nil)
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+c.token)
+		if resp.StatusCode == http.StatusUnauthorized && c.noReauth {
+			return fmt.Errorf("%w: %w", ErrUnauthorized, newStatusError(resp.StatusCode, body))
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			c.Debugf("401 for %s; waiting for re-authentication", req.URL)
+			if err := c.auth.pause(token); err != nil {
+				return fmt.Errorf("%w: %w", err, newStatusError(resp.StatusCode, body))
+			}
+			continue
+		}
 
-	if c.debugLog != nil {
-		c.debugLog.Printf("%s %s", req.Method, req.URL)
-	}
+		if resp.StatusCode != http.StatusOK {
+			return newStatusError(resp.StatusCode, body)
+		}
 
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("decoding response: %w", err)
+		}
+		return nil
 	}
-	defer resp.Body.Close()
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("reading response: %w", err)
-	}
+// StatusError is returned for any non-200 API response.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
 
-	if c.debugLog != nil {
-		c.debugLog.Printf("Response: %d (%d bytes)", resp.StatusCode, len(body))
-	}
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Message)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		var apiErr APIError
-		if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
-			return fmt.Errorf("API error %d: %s", resp.StatusCode, apiErr.Message)
-		}
-		return fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+func newStatusError(status int, body []byte) *StatusError {
+	var apiErr APIError
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+		return &StatusError{StatusCode: status, Message: apiErr.Message}
 	}
+	return &StatusError{StatusCode: status, Message: string(body)}
+}
 
-	if err := json.Unmarshal(body, out); err != nil {
-		return fmt.Errorf("decoding response: %w", err)
-	}
-	return nil
+// IsStatus reports whether err is a StatusError with the given code.
+func IsStatus(err error, code int) bool {
+	var se *StatusError
+	return errors.As(err, &se) && se.StatusCode == code
 }
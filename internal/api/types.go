@@ -126,6 +126,12 @@ type Project struct {
 	OrganizationID string `json:"organizationId"`
 }
 
+type User struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
 type APIError struct {
 	StatusCode int    `json:"statusCode"`
 	Error      string `json:"error"`
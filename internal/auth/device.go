@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// clientID identifies this tool to the authorization server.
+const clientID = "blueprints-tui"
+
+var (
+	ErrAccessDenied = errors.New("authorization was denied")
+	ErrExpired      = errors.New("authorization code expired")
+)
+
+// DeviceCode is the server's answer to starting a device authorization
+// (RFC 8628 §3.2).
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// BrowserURL returns the URL to open, preferring the one with the user
+// code already filled in.
+func (d DeviceCode) BrowserURL() string {
+	if d.VerificationURIComplete != "" {
+		return d.VerificationURIComplete
+	}
+	return d.VerificationURI
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// Client runs the device authorization flow against apiURL:
+//
+//	POST {apiURL}/v1/auth/device/code
+//	POST {apiURL}/v1/auth/device/token
+//
+// Pointing apiURL at a local server is enough to exercise the flow.
+type Client struct {
+	apiURL string
+	http   *http.Client
+	// second is how long the server's intervals and expiry count in;
+	// tests shorten it.
+	second time.Duration
+}
+
+func NewClient(apiURL string) *Client {
+	return &Client{
+		apiURL: strings.TrimRight(apiURL, "/"),
+		http:   &http.Client{Timeout: 30 * time.Second},
+		second: time.Second,
+	}
+}
+
+// Start requests a device and user code.
+func (c *Client) Start(ctx context.Context) (DeviceCode, error) {
+	var dc DeviceCode
+	form := url.Values{"client_id": {clientID}}
+	status, body, err := c.post(ctx, "/v1/auth/device/code", form)
+	if err != nil {
+		return dc, err
+	}
+	if status != http.StatusOK {
+		return dc, fmt.Errorf("starting login: HTTP %d: %s", status, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, &dc); err != nil {
+		return dc, fmt.Errorf("decoding device code: %w", err)
+	}
+	if dc.DeviceCode == "" || dc.BrowserURL() == "" {
+		return dc, fmt.Errorf("starting login: incomplete device code response")
+	}
+	return dc, nil
+}
+
+// Poll waits for the user to approve dc and returns the issued token. It
+// honours the server's interval and slow_down responses and gives up when
+// the code expires or ctx is cancelled.
+func (c *Client) Poll(ctx context.Context, dc DeviceCode) (string, error) {
+	interval := time.Duration(dc.Interval) * c.second
+	if interval <= 0 {
+		interval = 5 * c.second
+	}
+	if dc.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(dc.ExpiresIn)*c.second)
+		defer cancel()
+	}
+
+	form := url.Values{
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"device_code": {dc.DeviceCode},
+		"client_id":   {clientID},
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", ErrExpired
+			}
+			return "", ctx.Err()
+		case <-time.After(interval):
+		}
+
+		_, body, err := c.post(ctx, "/v1/auth/device/token", form)
+		if err != nil {
+			return "", err
+		}
+		var tr tokenResponse
+		if err := json.Unmarshal(body, &tr); err != nil {
+			return "", fmt.Errorf("decoding token response: %w", err)
+		}
+		switch tr.Error {
+		case "":
+			if tr.AccessToken == "" {
+				return "", fmt.Errorf("token response contained no token")
+			}
+			return tr.AccessToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * c.second
+		case "access_denied":
+			return "", ErrAccessDenied
+		case "expired_token":
+			return "", ErrExpired
+		default:
+			if tr.Description != "" {
+				return "", fmt.Errorf("%s: %s", tr.Error, tr.Description)
+			}
+			return "", errors.New(tr.Error)
+		}
+	}
+}
+
+func (c *Client) post(ctx context.Context, path string, form url.Values) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.apiURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("reading response: %w", err)
+	}
+	return resp.StatusCode, body, nil
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// deviceServer answers the device code request, then each token poll with
+// the next of responses, recording when each poll arrived.
+type deviceServer struct {
+	responses []string
+
+	mu    sync.Mutex
+	polls []time.Time
+}
+
+func (d *deviceServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("client_id") != clientID {
+		http.Error(w, "bad form", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/v1/auth/device/code":
+		fmt.Fprint(w, `{"device_code":"dev-1","user_code":"ABCD-EFGH","verification_uri":"https://example.com/device","expires_in":600,"interval":1}`)
+	case "/v1/auth/device/token":
+		if r.PostForm.Get("device_code") != "dev-1" {
+			http.Error(w, "unknown device code", http.StatusBadRequest)
+			return
+		}
+		d.mu.Lock()
+		n := len(d.polls)
+		d.polls = append(d.polls, time.Now())
+		d.mu.Unlock()
+		if n >= len(d.responses) {
+			http.Error(w, "polled too often", http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, d.responses[n])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestClient(t *testing.T, responses ...string) (*Client, *deviceServer) {
+	t.Helper()
+	d := &deviceServer{responses: responses}
+	srv := httptest.NewServer(d)
+	t.Cleanup(srv.Close)
+	c := NewClient(srv.URL + "/")
+	c.second = 10 * time.Millisecond
+	return c, d
+}
+
+func TestStart(t *testing.T) {
+	c, _ := newTestClient(t)
+	dc, err := c.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dc.DeviceCode != "dev-1" || dc.UserCode != "ABCD-EFGH" || dc.Interval != 1 {
+		t.Errorf("Start() = %+v", dc)
+	}
+	if got, want := dc.BrowserURL(), "https://example.com/device"; got != want {
+		t.Errorf("BrowserURL() = %q, want %q", got, want)
+	}
+}
+
+func TestPoll(t *testing.T) {
+	pending := `{"error":"authorization_pending"}`
+	tests := []struct {
+		name      string
+		responses []string
+		token     string
+		err       error
+	}{
+		{"success", []string{`{"access_token":"tok-1"}`}, "tok-1", nil},
+		{"pending then success", []string{pending, pending, `{"access_token":"tok-2"}`}, "tok-2", nil},
+		{"access denied", []string{pending, `{"error":"access_denied"}`}, "", ErrAccessDenied},
+		{"expired token", []string{`{"error":"expired_token"}`}, "", ErrExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, d := newTestClient(t, tt.responses...)
+			dc, err := c.Start(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, err := c.Poll(context.Background(), dc)
+			if !errors.Is(err, tt.err) || token != tt.token {
+				t.Errorf("Poll() = %q, %v; want %q, %v", token, err, tt.token, tt.err)
+			}
+			if len(d.polls) != len(tt.responses) {
+				t.Errorf("polled %d times, want %d", len(d.polls), len(tt.responses))
+			}
+		})
+	}
+}
+
+func TestPollSlowDown(t *testing.T) {
+	c, d := newTestClient(t, `{"error":"slow_down"}`, `{"access_token":"tok"}`)
+	dc, err := c.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Poll(context.Background(), dc); err != nil {
+		t.Fatal(err)
+	}
+	// The interval of one unit grows by five after slow_down.
+	if gap := d.polls[1].Sub(d.polls[0]); gap < 6*c.second {
+		t.Errorf("polled again after %s, want at least %s", gap, 6*c.second)
+	}
+}
+
+func TestPollExpires(t *testing.T) {
+	c, _ := newTestClient(t, `{"error":"authorization_pending"}`, `{"error":"authorization_pending"}`)
+	dc := DeviceCode{DeviceCode: "dev-1", Interval: 3, ExpiresIn: 5}
+	if _, err := c.Poll(context.Background(), dc); !errors.Is(err, ErrExpired) {
+		t.Errorf("Poll() error = %v, want %v", err, ErrExpired)
+	}
+}
@@ -1,102 +1,164 @@
 package config
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 )
 
+var (
+	// ErrNoToken is returned by Load when no token source yielded a token.
+	ErrNoToken = errors.New("no auth token found")
+	// ErrScopeConflict is returned by Load when more than one scope is set.
+	ErrScopeConflict = errors.New("conflicting scope")
+)
+
+// FileError is returned by Load when the config file exists but cannot be
+// read, parsed or validated.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("reading %s: %s", e.Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
 type Config struct {
-	Token     string
-	ScopeType string
-	ScopeID   string
-	APIURL    string
-	Debug     bool
+	Token        string
+	TokenSource  string
+	ScopeType    string
+	ScopeID      string
+	ScopeSource  string
+	APIURL       string
+	APIURLSource string
+	Debug        bool
+
+	File    File
+	Sources []TokenSource
 }
 
-type sanityConfig struct {
-	AuthToken string `json:"authToken"`
+// Flags holds the command-line values Load layers over env and file config.
+type Flags struct {
+	Token      string
+	TokenStdin bool
+	Org        string
+	Project    string
+	APIURL     string
+	Staging    bool
 }
 
-func Load(flagToken, flagOrg, flagProject, flagAPIURL string, staging bool) (Config, error) {
+// Load resolves the config from flags, env and the config file. It fills
+// in as much of Config as it can even when it returns an error, so that
+// callers such as doctor can report on the rest.
+func Load(flags Flags) (Config, error) {
 	cfg := Config{
-		APIURL: "https://api.sanity.io",
+		APIURL:       "https://api.sanity.io",
+		APIURLSource: "default",
 	}
-	if staging {
+	if flags.Staging {
 		cfg.APIURL = "https://api.sanity.work"
+		cfg.APIURLSource = "--staging flag"
 	}
-
-	if flagOrg != "" && flagProject != "" {
-		return cfg, fmt.Errorf("--org and --project are mutually exclusive")
+	switch {
+	case flags.APIURL != "":
+		cfg.APIURL = flags.APIURL
+		cfg.APIURLSource = "--api-url flag"
+	case os.Getenv("BLUEPRINTS_API_URL") != "":
+		cfg.APIURL = os.Getenv("BLUEPRINTS_API_URL")
+		cfg.APIURLSource = "BLUEPRINTS_API_URL"
 	}
 
-	cfg.Token = resolve(flagToken, "SANITY_AUTH_TOKEN", "")
-	if cfg.Token == "" {
-		t, err := readSanityToken(staging)
-		if err == nil {
-			cfg.Token = t
-		}
-	}
-	if cfg.Token == "" {
-		return cfg, fmt.Errorf("no auth token found (use --token, SANITY_AUTH_TOKEN, or log in with the Sanity CLI)")
+	file, err := LoadFile()
+	cfg.File = file
+	if err != nil {
+		return cfg, &FileError{Path: file.Path(), Err: err}
 	}
 
+	var scopeErr error
 	switch {
-	case flagOrg != "":
+	case flags.Org != "" && flags.Project != "":
+		scopeErr = fmt.Errorf("%w: --org and --project are mutually exclusive", ErrScopeConflict)
+	case flags.Org != "":
 		cfg.ScopeType = "organization"
-		cfg.ScopeID = flagOrg
-	case flagProject != "":
+		cfg.ScopeID = flags.Org
+		cfg.ScopeSource = "--org flag"
+	case flags.Project != "":
 		cfg.ScopeType = "project"
-		cfg.ScopeID = flagProject
+		cfg.ScopeID = flags.Project
+		cfg.ScopeSource = "--project flag"
 	default:
 		orgID := os.Getenv("SANITY_ORG_ID")
 		projectID := os.Getenv("SANITY_PROJECT_ID")
 		if orgID != "" && projectID != "" {
-			return cfg, fmt.Errorf("SANITY_ORG_ID and SANITY_PROJECT_ID are mutually exclusive")
-		}
-		if orgID != "" {
+			scopeErr = fmt.Errorf("%w: SANITY_ORG_ID and SANITY_PROJECT_ID are mutually exclusive", ErrScopeConflict)
+		} else if orgID != "" {
 			cfg.ScopeType = "organization"
 			cfg.ScopeID = orgID
+			cfg.ScopeSource = "SANITY_ORG_ID"
 		} else if projectID != "" {
 			cfg.ScopeType = "project"
 			cfg.ScopeID = projectID
+			cfg.ScopeSource = "SANITY_PROJECT_ID"
 		}
 	}
 
-	if u := resolve(flagAPIURL, "BLUEPRINTS_API_URL", ""); u != "" {
-		cfg.APIURL = u
+	// Resolved last so that callers handling ErrNoToken (login, the
+	// first-run screen) still receive a fully populated Config.
+	cfg.Sources = tokenSources(flags, file, cfg.APIURL, os.Stdin)
+	cfg.Token, cfg.TokenSource, err = ResolveToken(cfg.Sources)
+	if scopeErr != nil {
+		return cfg, scopeErr
 	}
+	if errors.Is(err, ErrNoToken) {
+		return cfg, fmt.Errorf("%w; run `blueprints-tui login`, or use --token, SANITY_AUTH_TOKEN, token_command/token_file in %s, or the Sanity CLI", err, file.Path())
+	}
+	return cfg, err
+}
 
-	return cfg, nil
+// Conflict describes flags and env vars that contradict each other.
+// Fatal conflicts make Load fail; the rest are silently resolved by
+// precedence and only reported by doctor.
+type Conflict struct {
+	Fatal   bool
+	Message string
 }
 
-func resolve(flag, envKey, fallback string) string {
-	if flag != "" {
-		return flag
+// Conflicts lists contradicting flag and env var combinations.
+func Conflicts(flags Flags) []Conflict {
+	orgEnv := os.Getenv("SANITY_ORG_ID")
+	projectEnv := os.Getenv("SANITY_PROJECT_ID")
+	apiEnv := os.Getenv("BLUEPRINTS_API_URL")
+
+	var cs []Conflict
+	if flags.Org != "" && flags.Project != "" {
+		cs = append(cs, Conflict{Fatal: true, Message: "--org and --project are mutually exclusive"})
 	}
-	if v := os.Getenv(envKey); v != "" {
-		return v
+	if orgEnv != "" && projectEnv != "" {
+		if flags.Org == "" && flags.Project == "" {
+			cs = append(cs, Conflict{Fatal: true, Message: "SANITY_ORG_ID and SANITY_PROJECT_ID are mutually exclusive"})
+		} else {
+			cs = append(cs, Conflict{Message: "SANITY_ORG_ID and SANITY_PROJECT_ID are both set (ignored because a scope flag was given)"})
+		}
 	}
-	return fallback
-}
-
-func readSanityToken(staging bool) (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
+	if flags.Org != "" && projectEnv != "" {
+		cs = append(cs, Conflict{Message: "--org overrides SANITY_PROJECT_ID"})
 	}
-	configDir := "sanity"
-	if staging {
-		configDir = "sanity-staging"
+	if flags.Project != "" && orgEnv != "" {
+		cs = append(cs, Conflict{Message: "--project overrides SANITY_ORG_ID"})
 	}
-	data, err := os.ReadFile(filepath.Join(home, ".config", configDir, "config.json"))
-	if err != nil {
-		return "", err
+	if flags.Token != "" && os.Getenv("SANITY_AUTH_TOKEN") != "" {
+		cs = append(cs, Conflict{Message: "--token overrides SANITY_AUTH_TOKEN"})
+	}
+	if flags.Staging && (flags.APIURL != "" || apiEnv != "") {
+		cs = append(cs, Conflict{Message: "--staging is ignored because the API URL is set explicitly"})
 	}
-	var sc sanityConfig
-	if err := json.Unmarshal(data, &sc); err != nil {
-		return "", err
+	if flags.APIURL != "" && apiEnv != "" {
+		cs = append(cs, Conflict{Message: "--api-url overrides BLUEPRINTS_API_URL"})
 	}
-	return sc.AuthToken, nil
+	return cs
 }
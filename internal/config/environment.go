@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Environment is another API endpoint to compare stacks against, declared
+// in the config file:
+//
+//	[environments.staging]
+//	api_url       = "https://api.sanity.work"
+//	token_command = "op read op://vault/sanity-staging/token"
+//
+// Its token comes from token_command, token_file or the keyring when set,
+// falling back to the Sanity CLI login for the environment's host.
+type Environment struct {
+	APIURL       string `toml:"api_url"`
+	TokenCommand string `toml:"token_command"`
+	TokenFile    string `toml:"token_file"`
+	Keyring      bool   `toml:"keyring"`
+}
+
+// EnvironmentNames returns the configured environment names, sorted.
+func (f File) EnvironmentNames() []string {
+	names := make([]string, 0, len(f.Environments))
+	for name := range f.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Sources returns the token sources for the environment in precedence
+// order: token_command, token_file, keyring, Sanity CLI config.
+func (e Environment) Sources() []TokenSource {
+	var sources []TokenSource
+	if e.TokenCommand != "" {
+		sources = append(sources, commandSource{command: e.TokenCommand})
+	}
+	if e.TokenFile != "" {
+		sources = append(sources, fileSource{path: expandHome(e.TokenFile)})
+	}
+	if e.Keyring {
+		sources = append(sources, keyringSource{account: keyringAccount(e.APIURL)})
+	}
+	sources = append(sources, sanityCLISource{staging: isStagingURL(e.APIURL)})
+	return sources
+}
+
+// Validate reports an environment without a usable api_url.
+func (e Environment) Validate(name string) error {
+	u, err := url.Parse(e.APIURL)
+	if e.APIURL == "" || err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("environment %q: api_url must be an absolute URL", name)
+	}
+	return nil
+}
+
+func isStagingURL(apiURL string) bool {
+	u, err := url.Parse(apiURL)
+	return err == nil && strings.HasSuffix(u.Hostname(), "sanity.work")
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// File is the optional TOML config file. Every key is optional:
+//
+//	token_command = "op read op://vault/sanity/token"
+//	token_file    = "~/.config/blueprints-tui/token"
+//	keyring       = true
+//	resume_scope  = true
+//	restore_session = false
+//
+// Extra API endpoints for stack comparison go in [environments.<name>]
+// tables; see Environment. The stack list layout is set in [stack_list];
+// see StackList. Key bindings are changed in [keys]; see Keys.
+type File struct {
+	TokenCommand string `toml:"token_command"`
+	TokenFile    string `toml:"token_file"`
+	Keyring      bool   `toml:"keyring"`
+	ResumeScope  bool   `toml:"resume_scope"`
+	// RestoreSession defaults to true; see RestoresSession.
+	RestoreSession *bool `toml:"restore_session"`
+
+	Environments map[string]Environment `toml:"environments"`
+	StackList    StackList              `toml:"stack_list"`
+	Keys         Keys                   `toml:"keys"`
+
+	path  string
+	found bool
+}
+
+// Dir returns the blueprints-tui config directory, ~/.config/blueprints-tui.
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "blueprints-tui"), nil
+}
+
+// FilePath returns the config file location. BLUEPRINTS_TUI_CONFIG
+// overrides the default of ~/.config/blueprints-tui/config.toml.
+func FilePath() (string, error) {
+	if p := os.Getenv("BLUEPRINTS_TUI_CONFIG"); p != "" {
+		return p, nil
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// LoadFile reads the config file. A missing file is not an error and
+// yields the zero File.
+func LoadFile() (File, error) {
+	var f File
+	p, err := FilePath()
+	if err != nil {
+		return f, err
+	}
+	f.path = p
+	if _, err := toml.DecodeFile(p, &f); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return f, nil
+		}
+		return f, err
+	}
+	f.found = true
+	for _, name := range f.EnvironmentNames() {
+		if err := f.Environments[name].Validate(name); err != nil {
+			return f, err
+		}
+	}
+	if err := f.StackList.Validate(); err != nil {
+		return f, err
+	}
+	if err := f.Keys.Validate(); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// Path returns where the file was (or would have been) loaded from.
+func (f File) Path() string {
+	return f.path
+}
+
+// Found reports whether the file existed.
+func (f File) Found() bool {
+	return f.found
+}
+
+// RestoresSession reports whether the last view stack should be reopened
+// on startup. It is on unless restore_session = false.
+func (f File) RestoresSession() bool {
+	return f.RestoreSession == nil || *f.RestoreSession
+}
+
+// tokenFilePath returns token_file with ~ expanded, defaulting to
+// ~/.config/blueprints-tui/token.
+func (f File) tokenFilePath() string {
+	if f.TokenFile != "" {
+		return expandHome(f.TokenFile)
+	}
+	dir, err := Dir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "token")
+}
+
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, strings.TrimPrefix(p, "~"))
+}
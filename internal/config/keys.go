@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Keys rebinds the TUI's actions, declared in the config file:
+//
+//	[keys]
+//	refresh = "ctrl+l"
+//	down    = ["down", "j", "ctrl+n"]
+//
+// Each action takes a key or a list of keys, which replace its defaults.
+// The action names and the key syntax are checked by the TUI at startup.
+type Keys map[string]KeyList
+
+// KeyList is the keys bound to one action: a string or an array of them.
+type KeyList []string
+
+// UnmarshalTOML accepts a single key as well as an array of keys.
+func (k *KeyList) UnmarshalTOML(v any) error {
+	switch v := v.(type) {
+	case string:
+		*k = KeyList{v}
+		return nil
+	case []any:
+		keys := make(KeyList, 0, len(v))
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return fmt.Errorf("keys must be strings, not %T", e)
+			}
+			keys = append(keys, s)
+		}
+		*k = keys
+		return nil
+	}
+	return fmt.Errorf("keys must be a string or an array of strings, not %T", v)
+}
+
+// Actions returns the rebound action names, sorted.
+func (k Keys) Actions() []string {
+	names := make([]string, 0, len(k))
+	for name := range k {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate reports an action left without keys or given a blank one.
+func (k Keys) Validate() error {
+	for _, name := range k.Actions() {
+		if len(k[name]) == 0 {
+			return fmt.Errorf("keys: no keys given for %q", name)
+		}
+		for _, key := range k[name] {
+			if key == "" {
+				return fmt.Errorf("keys: empty key given for %q", name)
+			}
+		}
+	}
+	return nil
+}
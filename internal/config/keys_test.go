@@ -0,0 +1,84 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestKeysDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		toml string
+		want Keys
+		err  string
+	}{
+		{
+			name: "string",
+			toml: `refresh = "ctrl+l"`,
+			want: Keys{"refresh": {"ctrl+l"}},
+		},
+		{
+			name: "array",
+			toml: `down = ["down", "j", "ctrl+n"]`,
+			want: Keys{"down": {"down", "j", "ctrl+n"}},
+		},
+		{
+			name: "both",
+			toml: "quit = \"Q\"\nup = [\"up\", \"w\"]",
+			want: Keys{"quit": {"Q"}, "up": {"up", "w"}},
+		},
+		{
+			name: "number",
+			toml: `refresh = 3`,
+			err:  "must be a string or an array of strings",
+		},
+		{
+			name: "array of numbers",
+			toml: `refresh = [1, 2]`,
+			err:  "keys must be strings",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f File
+			_, err := toml.Decode("[keys]\n"+tt.toml, &f)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Decode() error = %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(f.Keys) != len(tt.want) {
+				t.Fatalf("Keys = %v, want %v", f.Keys, tt.want)
+			}
+			for name, keys := range tt.want {
+				if !slices.Equal(f.Keys[name], keys) {
+					t.Errorf("Keys[%q] = %v, want %v", name, f.Keys[name], keys)
+				}
+			}
+		})
+	}
+}
+
+func TestKeysValidate(t *testing.T) {
+	tests := []struct {
+		keys Keys
+		err  string
+	}{
+		{Keys{"refresh": {"ctrl+l"}}, ""},
+		{Keys{"refresh": {}}, `no keys given for "refresh"`},
+		{Keys{"refresh": {"r", ""}}, `empty key given for "refresh"`},
+	}
+	for _, tt := range tests {
+		err := tt.keys.Validate()
+		if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%v.Validate() = %v, want %q", tt.keys, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// StackColumns are the columns the stack table can show, in the order
+// shown by default.
+var StackColumns = []string{"name", "id", "blueprint", "resources", "status", "age", "updated"}
+
+// StackList configures the stack list, declared in the config file:
+//
+//	[stack_list]
+//	layout  = "table"
+//	columns = ["name", "status", "age", "resources"]
+//
+// layout is "list" (the default) or "table"; columns picks and orders the
+// table's columns from StackColumns.
+type StackList struct {
+	Layout  string   `toml:"layout"`
+	Columns []string `toml:"columns"`
+}
+
+// Validate reports an unknown layout or column, or a column listed twice.
+func (s StackList) Validate() error {
+	switch s.Layout {
+	case "", "list", "table":
+	default:
+		return fmt.Errorf("stack_list: layout must be \"list\" or \"table\", not %q", s.Layout)
+	}
+	for i, c := range s.Columns {
+		if !slices.Contains(StackColumns, c) {
+			return fmt.Errorf("stack_list: unknown column %q (known: %s)", c, strings.Join(StackColumns, ", "))
+		}
+		if slices.Contains(s.Columns[:i], c) {
+			return fmt.Errorf("stack_list: column %q is listed twice", c)
+		}
+	}
+	return nil
+}
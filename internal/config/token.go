@@ -0,0 +1,286 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService is the service name tokens are stored under in the OS
+// keyring. The account is the API host so staging and production tokens
+// can coexist.
+const keyringService = "blueprints-tui"
+
+// ErrTokenNotFound is returned by a TokenSource that is configured but
+// currently holds no token. ResolveToken skips such sources.
+var ErrTokenNotFound = errors.New("token not found")
+
+// TokenSource resolves an auth token from a single place.
+type TokenSource interface {
+	// Name describes the source for diagnostics, e.g. "token_command".
+	Name() string
+	// Token returns the token, or ErrTokenNotFound if the source is empty.
+	Token() (string, error)
+}
+
+// ResolveToken walks sources in precedence order and returns the first
+// token found along with the name of the source that supplied it. A source
+// that fails for any reason other than ErrTokenNotFound stops the walk, so
+// a broken token_command is reported instead of silently skipped.
+func ResolveToken(sources []TokenSource) (token, source string, err error) {
+	var tried []string
+	for _, src := range sources {
+		t, err := src.Token()
+		if errors.Is(err, ErrTokenNotFound) {
+			tried = append(tried, src.Name())
+			continue
+		}
+		if err != nil {
+			return "", src.Name(), fmt.Errorf("%s: %w", src.Name(), err)
+		}
+		return t, src.Name(), nil
+	}
+	return "", "", fmt.Errorf("%w (tried %s)", ErrNoToken, strings.Join(tried, ", "))
+}
+
+type staticSource struct {
+	name  string
+	value string
+}
+
+func (s staticSource) Name() string { return s.name }
+
+func (s staticSource) Token() (string, error) {
+	if s.value == "" {
+		return "", ErrTokenNotFound
+	}
+	return s.value, nil
+}
+
+// stdinSource reads the token from r once and caches it, so the source can
+// be consulted again (e.g. on re-authentication) after stdin is drained.
+type stdinSource struct {
+	r     io.Reader
+	once  sync.Once
+	token string
+	err   error
+}
+
+func (s *stdinSource) Name() string { return "stdin" }
+
+func (s *stdinSource) Token() (string, error) {
+	s.once.Do(func() {
+		data, err := io.ReadAll(s.r)
+		if err != nil {
+			s.err = err
+			return
+		}
+		s.token = strings.TrimSpace(string(data))
+	})
+	if s.err != nil {
+		return "", s.err
+	}
+	if s.token == "" {
+		return "", ErrTokenNotFound
+	}
+	return s.token, nil
+}
+
+// commandSource runs a credential helper such as `op read ...` through the
+// shell and uses its trimmed stdout as the token.
+type commandSource struct {
+	command string
+}
+
+func (s commandSource) Name() string { return "token_command" }
+
+func (s commandSource) Token() (string, error) {
+	cmd := exec.Command("sh", "-c", s.command)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	t := strings.TrimSpace(string(out))
+	if t == "" {
+		return "", fmt.Errorf("command printed no token")
+	}
+	return t, nil
+}
+
+type fileSource struct {
+	path string
+}
+
+func (s fileSource) Name() string { return "token_file (" + s.path + ")" }
+
+func (s fileSource) Token() (string, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrTokenNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	t := strings.TrimSpace(string(data))
+	if t == "" {
+		return "", ErrTokenNotFound
+	}
+	return t, nil
+}
+
+type keyringSource struct {
+	account string
+}
+
+func (s keyringSource) Name() string { return "keyring (" + keyringService + "/" + s.account + ")" }
+
+func (s keyringSource) Token() (string, error) {
+	t, err := keyring.Get(keyringService, s.account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrTokenNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return t, nil
+}
+
+type sanityConfig struct {
+	AuthToken string `json:"authToken"`
+}
+
+// sanityCLISource reads the token the Sanity CLI stores after `sanity login`.
+type sanityCLISource struct {
+	staging bool
+}
+
+func (s sanityCLISource) path() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	configDir := "sanity"
+	if s.staging {
+		configDir = "sanity-staging"
+	}
+	return filepath.Join(home, ".config", configDir, "config.json"), nil
+}
+
+func (s sanityCLISource) Name() string {
+	p, err := s.path()
+	if err != nil {
+		return "Sanity CLI config"
+	}
+	return "Sanity CLI config (" + p + ")"
+}
+
+func (s sanityCLISource) Token() (string, error) {
+	p, err := s.path()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrTokenNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	var sc sanityConfig
+	if err := json.Unmarshal(data, &sc); err != nil {
+		return "", err
+	}
+	if sc.AuthToken == "" {
+		return "", ErrTokenNotFound
+	}
+	return sc.AuthToken, nil
+}
+
+// tokenSources builds the token sources in precedence order:
+//
+//	--token, --token-stdin, SANITY_AUTH_TOKEN, token_command, token_file,
+//	keyring, Sanity CLI config
+//
+// token_command and keyring are only consulted when configured.
+func tokenSources(flags Flags, file File, apiURL string, stdin io.Reader) []TokenSource {
+	sources := []TokenSource{
+		staticSource{name: "--token flag", value: flags.Token},
+	}
+	if flags.TokenStdin {
+		sources = append(sources, &stdinSource{r: stdin})
+	}
+	sources = append(sources, staticSource{name: "SANITY_AUTH_TOKEN", value: os.Getenv("SANITY_AUTH_TOKEN")})
+	if file.TokenCommand != "" {
+		sources = append(sources, commandSource{command: file.TokenCommand})
+	}
+	if p := file.tokenFilePath(); p != "" {
+		sources = append(sources, fileSource{path: p})
+	}
+	if file.Keyring {
+		sources = append(sources, keyringSource{account: keyringAccount(apiURL)})
+	}
+	sources = append(sources, sanityCLISource{staging: flags.Staging})
+	return sources
+}
+
+func keyringAccount(apiURL string) string {
+	if u, err := url.Parse(apiURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return apiURL
+}
+
+// TokenStore is a TokenSource that can also persist a token, used by login.
+type TokenStore interface {
+	TokenSource
+	Store(token string) error
+}
+
+func (s fileSource) Store(token string) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, []byte(token+"\n"), 0o600)
+}
+
+func (s keyringSource) Store(token string) error {
+	return keyring.Set(keyringService, s.account, token)
+}
+
+// Store returns the named token store: "file" (token_file) or "keyring".
+// An empty name picks the keyring when it is enabled in the config file and
+// token_file otherwise.
+func (c Config) Store(name string) (TokenStore, error) {
+	if name == "" {
+		name = "file"
+		if c.File.Keyring {
+			name = "keyring"
+		}
+	}
+	switch name {
+	case "file":
+		p := c.File.tokenFilePath()
+		if p == "" {
+			return nil, fmt.Errorf("cannot determine token file location")
+		}
+		return fileSource{path: p}, nil
+	case "keyring":
+		return keyringSource{account: keyringAccount(c.APIURL)}, nil
+	}
+	return nil, fmt.Errorf("unknown token store %q (want file or keyring)", name)
+}
@@ -0,0 +1,105 @@
+// Package deeplink parses blueprints-tui:// links that point at a stack,
+// resource or operation:
+//
+//	blueprints-tui://stack/<stack>[/resource/<resource> | /operation/<operation>][?project=<id> | ?org=<id>]
+//	blueprints-tui://operation/<operation>[?project=<id> | ?org=<id>]
+//	blueprints-tui://resource/<resource>[?project=<id> | ?org=<id>]
+package deeplink
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const Scheme = "blueprints-tui"
+
+// Link is a parsed deep link. ScopeType and ScopeID are empty when the
+// link does not say which scope the stack lives in.
+type Link struct {
+	ScopeType   string
+	ScopeID     string
+	StackID     string
+	ResourceID  string
+	OperationID string
+}
+
+// IsLink reports whether s looks like a blueprints-tui:// link.
+func IsLink(s string) bool {
+	return strings.HasPrefix(s, Scheme+"://")
+}
+
+func Parse(s string) (Link, error) {
+	var l Link
+	u, err := url.Parse(s)
+	if err != nil {
+		return l, fmt.Errorf("invalid link: %w", err)
+	}
+	if u.Scheme != Scheme {
+		return l, fmt.Errorf("invalid link %q: scheme must be %s://", s, Scheme)
+	}
+
+	parts := []string{u.Host}
+	parts = append(parts, strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })...)
+	if len(parts)%2 != 0 {
+		return l, fmt.Errorf("invalid link %q: expected kind/id pairs", s)
+	}
+	for i := 0; i < len(parts); i += 2 {
+		kind, id := parts[i], parts[i+1]
+		switch kind {
+		case "stack", "stacks":
+			l.StackID = id
+		case "resource", "resources":
+			l.ResourceID = id
+		case "operation", "operations":
+			l.OperationID = id
+		default:
+			return l, fmt.Errorf("invalid link %q: unknown segment %q", s, kind)
+		}
+	}
+
+	q := u.Query()
+	switch {
+	case q.Get("project") != "" && q.Get("org") != "":
+		return l, fmt.Errorf("invalid link %q: project and org are mutually exclusive", s)
+	case q.Get("project") != "":
+		l.ScopeType, l.ScopeID = "project", q.Get("project")
+	case q.Get("org") != "":
+		l.ScopeType, l.ScopeID = "organization", q.Get("org")
+	}
+
+	return l, l.Validate()
+}
+
+// Validate checks that the link names something to open.
+func (l Link) Validate() error {
+	switch {
+	case l.ResourceID != "" && l.OperationID != "":
+		return fmt.Errorf("a link opens either a resource or an operation, not both")
+	case l.StackID == "" && l.ResourceID == "" && l.OperationID == "":
+		return fmt.Errorf("a link needs a stack, resource or operation")
+	}
+	return nil
+}
+
+// String formats l as a blueprints-tui:// link.
+func (l Link) String() string {
+	var path []string
+	if l.StackID != "" {
+		path = append(path, "stack", l.StackID)
+	}
+	switch {
+	case l.ResourceID != "":
+		path = append(path, "resource", l.ResourceID)
+	case l.OperationID != "":
+		path = append(path, "operation", l.OperationID)
+	}
+	s := Scheme + "://" + strings.Join(path, "/")
+	switch l.ScopeType {
+	case "project":
+		s += "?project=" + url.QueryEscape(l.ScopeID)
+	case "organization":
+		s += "?org=" + url.QueryEscape(l.ScopeID)
+	}
+	return s
+}
@@ -0,0 +1,95 @@
+package deeplink
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		link string
+		want Link
+		err  string
+	}{
+		{
+			link: "blueprints-tui://stack/st1",
+			want: Link{StackID: "st1"},
+		},
+		{
+			link: "blueprints-tui://stack/st1/resource/r1",
+			want: Link{StackID: "st1", ResourceID: "r1"},
+		},
+		{
+			link: "blueprints-tui://stacks/st1/operations/op1?project=p1",
+			want: Link{ScopeType: "project", ScopeID: "p1", StackID: "st1", OperationID: "op1"},
+		},
+		{
+			link: "blueprints-tui://operation/op1?org=o1",
+			want: Link{ScopeType: "organization", ScopeID: "o1", OperationID: "op1"},
+		},
+		{
+			link: "blueprints-tui://resource/r1/",
+			want: Link{ResourceID: "r1"},
+		},
+		{link: "https://stack/st1", err: "scheme must be blueprints-tui://"},
+		{link: "blueprints-tui://stack", err: "expected kind/id pairs"},
+		{link: "blueprints-tui://stack/st1/resource", err: "expected kind/id pairs"},
+		{link: "blueprints-tui://", err: "expected kind/id pairs"},
+		{link: "blueprints-tui://blueprint/bp1", err: `unknown segment "blueprint"`},
+		{link: "blueprints-tui://stack/st1?project=p1&org=o1", err: "mutually exclusive"},
+		{link: "blueprints-tui://stack/st1/resource/r1/operation/op1", err: "either a resource or an operation"},
+		{link: "blueprints-tui://stack/%zz", err: "invalid link"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			got, err := Parse(tt.link)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Parse() error = %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	for _, l := range []Link{
+		{StackID: "st1"},
+		{StackID: "st1", ResourceID: "r1", ScopeType: "project", ScopeID: "p1"},
+		{StackID: "st1", OperationID: "op1", ScopeType: "organization", ScopeID: "o1"},
+		{OperationID: "op1"},
+		{ResourceID: "r1", ScopeType: "project", ScopeID: "p 1&x"},
+	} {
+		s := l.String()
+		if !IsLink(s) {
+			t.Errorf("IsLink(%q) = false", s)
+		}
+		got, err := Parse(s)
+		if err != nil {
+			t.Errorf("Parse(%q) = %v", s, err)
+			continue
+		}
+		if got != l {
+			t.Errorf("Parse(%q) = %+v, want %+v", s, got, l)
+		}
+	}
+}
+
+func TestIsLink(t *testing.T) {
+	for s, want := range map[string]bool{
+		"blueprints-tui://stack/st1": true,
+		"blueprints-tui:stack/st1":   false,
+		"st1":                        false,
+	} {
+		if got := IsLink(s); got != want {
+			t.Errorf("IsLink(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// Package doctor diagnoses config, token and connectivity problems and
+// prints a pass/fail report.
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+	"github.com/sanity-labs/blueprints-tui/internal/config"
+)
+
+const requestTimeout = 15 * time.Second
+
+type result int
+
+const (
+	pass result = iota
+	warn
+	fail
+)
+
+func (r result) mark() string {
+	switch r {
+	case pass:
+		return "✓"
+	case warn:
+		return "!"
+	}
+	return "✗"
+}
+
+type report struct {
+	w        io.Writer
+	failures int
+	warnings int
+}
+
+func (r *report) section(title string) {
+	fmt.Fprintf(r.w, "\n%s\n", title)
+}
+
+func (r *report) check(res result, label, format string, args ...any) {
+	switch res {
+	case warn:
+		r.warnings++
+	case fail:
+		r.failures++
+	}
+	fmt.Fprintf(r.w, "  %s %-16s %s\n", res.mark(), label, fmt.Sprintf(format, args...))
+}
+
+// Run prints the report to w and returns false if any check failed.
+func Run(w io.Writer, flags config.Flags) bool {
+	r := &report{w: w}
+	cfg, loadErr := config.Load(flags)
+
+	r.section("Config")
+	checkConfig(r, flags, cfg, loadErr)
+
+	if cfg.Token == "" {
+		r.summary()
+		return false
+	}
+
+	client := api.NewClient(cfg.APIURL, cfg.Token, cfg.ScopeType, cfg.ScopeID, false)
+	client.SetTimeout(requestTimeout)
+	// Doctor has no one to ask for a new token, so a 401 fails the check.
+	client.DisableReauth()
+
+	r.section("Token")
+	projects, ok := checkToken(r, client)
+
+	r.section("Connectivity")
+	checkConnectivity(r, client, cfg, projects, ok)
+
+	r.summary()
+	return r.failures == 0
+}
+
+func checkConfig(r *report, flags config.Flags, cfg config.Config, loadErr error) {
+	var fileErr *config.FileError
+	switch {
+	case errors.As(loadErr, &fileErr):
+		r.check(fail, "config file", "%s: %s", fileErr.Path, fileErr.Err)
+	case cfg.File.Path() != "":
+		r.check(pass, "config file", "%s", describeFile(cfg.File))
+	}
+	r.check(pass, "API URL", "%s (from %s)", cfg.APIURL, cfg.APIURLSource)
+
+	switch {
+	case fileErr != nil:
+		// Token and scope resolution stop at an unreadable file.
+		r.check(warn, "token", "not resolved until the config file is fixed")
+	case cfg.Token != "":
+		r.check(pass, "token", "%s (from %s)", api.Redact(cfg.Token), cfg.TokenSource)
+	case loadErr != nil && !errors.Is(loadErr, config.ErrScopeConflict):
+		r.check(fail, "token", "%s", loadErr)
+	default:
+		r.check(fail, "token", "none found")
+	}
+
+	switch {
+	case fileErr != nil:
+	case cfg.ScopeID != "":
+		r.check(pass, "scope", "%s %s (from %s)", cfg.ScopeType, cfg.ScopeID, cfg.ScopeSource)
+	case !errors.Is(loadErr, config.ErrScopeConflict):
+		r.check(pass, "scope", "none; the scope picker will be shown")
+	}
+
+	conflicts := config.Conflicts(flags)
+	for _, c := range conflicts {
+		if c.Fatal {
+			r.check(fail, "conflict", "%s", c.Message)
+		} else {
+			r.check(warn, "conflict", "%s", c.Message)
+		}
+	}
+	if len(conflicts) == 0 {
+		r.check(pass, "conflicts", "no conflicting flags or env vars")
+	}
+}
+
+func checkToken(r *report, client *api.Client) ([]api.Project, bool) {
+	start := time.Now()
+	user, err := client.Whoami()
+	if err != nil {
+		r.check(fail, "valid", "%s", err)
+		return nil, false
+	}
+	who := user.Name
+	if user.Email != "" {
+		who += " <" + user.Email + ">"
+	}
+	r.check(pass, "valid", "%s (%s)", who, since(start))
+
+	orgs, err := client.ListOrganizations()
+	if err != nil {
+		r.check(fail, "organizations", "%s", err)
+	} else if len(orgs) == 0 {
+		r.check(warn, "organizations", "token can see no organizations")
+	} else {
+		names := make([]string, len(orgs))
+		for i, o := range orgs {
+			names[i] = fmt.Sprintf("%s (%s)", o.Name, o.ID)
+		}
+		sort.Strings(names)
+		r.check(pass, "organizations", "%d: %s", len(orgs), truncateList(names, 5))
+	}
+
+	projects, err := client.ListProjects()
+	if err != nil {
+		r.check(fail, "projects", "%s", err)
+	} else if len(projects) == 0 {
+		r.check(warn, "projects", "token can see no projects")
+	} else {
+		names := make([]string, len(projects))
+		for i, p := range projects {
+			names[i] = fmt.Sprintf("%s (%s)", p.DisplayName, p.ID)
+		}
+		sort.Strings(names)
+		r.check(pass, "projects", "%d: %s", len(projects), truncateList(names, 5))
+	}
+	return projects, true
+}
+
+// checkConnectivity times one request against each API. Any HTTP response
+// proves the endpoint is reachable; only transport errors fail the check.
+func checkConnectivity(r *report, client *api.Client, cfg config.Config, projects []api.Project, tokenOK bool) {
+	start := time.Now()
+	_, err := client.Whoami()
+	reachability(r, "management API", cfg.APIURL, start, err)
+
+	scopeType, scopeID := cfg.ScopeType, cfg.ScopeID
+	if scopeID == "" && len(projects) > 0 {
+		scopeType, scopeID = "project", projects[0].ID
+	}
+	if scopeID == "" {
+		r.check(warn, "Blueprints API", "%s (skipped: no scope to query)", client.BaseURL())
+		return
+	}
+	start = time.Now()
+	stacks, err := client.WithScope(scopeType, scopeID).ListStacks()
+	reachability(r, "Blueprints API", client.BaseURL(), start, err)
+	if err == nil {
+		r.check(pass, "stacks", "%d in %s %s", len(stacks), scopeType, scopeID)
+	} else if tokenOK && isHTTP(err) {
+		r.check(fail, "stacks", "listing stacks in %s %s: %s", scopeType, scopeID, err)
+	}
+}
+
+func reachability(r *report, label, url string, start time.Time, err error) {
+	switch {
+	case err == nil:
+		r.check(pass, label, "%s (%s)", url, since(start))
+	case isHTTP(err):
+		r.check(pass, label, "%s reachable, responded with an error (%s)", url, since(start))
+	default:
+		r.check(fail, label, "%s unreachable: %s", url, err)
+	}
+}
+
+func (r *report) summary() {
+	fmt.Fprintln(r.w)
+	switch {
+	case r.failures > 0:
+		fmt.Fprintf(r.w, "%d check(s) failed, %d warning(s)\n", r.failures, r.warnings)
+	case r.warnings > 0:
+		fmt.Fprintf(r.w, "All checks passed with %d warning(s)\n", r.warnings)
+	default:
+		fmt.Fprintln(r.w, "All checks passed")
+	}
+}
+
+func describeFile(f config.File) string {
+	if !f.Found() {
+		return f.Path() + " (not found, using defaults)"
+	}
+	var set []string
+	if f.TokenCommand != "" {
+		set = append(set, "token_command")
+	}
+	if f.TokenFile != "" {
+		set = append(set, "token_file")
+	}
+	if f.Keyring {
+		set = append(set, "keyring")
+	}
+	if len(set) == 0 {
+		return f.Path() + " (no token settings)"
+	}
+	return f.Path() + " (" + strings.Join(set, ", ") + ")"
+}
+
+func isHTTP(err error) bool {
+	var se *api.StatusError
+	return errors.As(err, &se)
+}
+
+func since(t time.Time) string {
+	return time.Since(t).Round(time.Millisecond).String()
+}
+
+func truncateList(items []string, n int) string {
+	if len(items) <= n {
+		return strings.Join(items, ", ")
+	}
+	return strings.Join(items[:n], ", ") + fmt.Sprintf(", … %d more", len(items)-n)
+}
@@ -0,0 +1,147 @@
+package doctor
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sanity-labs/blueprints-tui/internal/config"
+)
+
+// apiServer answers the endpoints doctor calls, failing any path listed in
+// status with that status code.
+func apiServer(t *testing.T, status map[string]int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if code, ok := status[r.URL.Path]; ok {
+			http.Error(w, `{"message":"nope"}`, code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2021-06-07/users/me":
+			fmt.Fprint(w, `{"id":"u1","name":"Ada","email":"ada@example.com"}`)
+		case "/v2021-06-07/organizations":
+			fmt.Fprint(w, `[{"id":"o1","name":"Acme"}]`)
+		case "/v2021-06-07/projects":
+			fmt.Fprint(w, `[{"id":"p1","displayName":"Shop","organizationId":"o1"}]`)
+		case "/vX/blueprints/stacks":
+			fmt.Fprint(w, `[{"id":"st1","name":"production"}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRun(t *testing.T) {
+	const (
+		me     = "/v2021-06-07/users/me"
+		stacks = "/vX/blueprints/stacks"
+	)
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name   string
+		status map[string]int
+		apiURL string // overrides the test server's URL
+		file   string
+		ok     bool
+		want   []string
+		absent []string
+	}{
+		{
+			name: "all pass",
+			ok:   true,
+			want: []string{
+				"✓ valid            Ada <ada@example.com>",
+				"✓ organizations    1: Acme (o1)",
+				"✓ projects         1: Shop (p1)",
+				"✓ stacks           1 in project p1",
+				"All checks passed",
+			},
+		},
+		{
+			name:   "unauthorized token",
+			status: map[string]int{me: http.StatusUnauthorized},
+			want: []string{
+				"✗ valid",
+				"✓ management API",
+				"reachable, responded with an error",
+			},
+			absent: []string{"✓ organizations"},
+		},
+		{
+			name:   "stacks fail",
+			status: map[string]int{stacks: http.StatusInternalServerError},
+			want: []string{
+				"✓ Blueprints API",
+				"✗ stacks           listing stacks in project p1",
+			},
+		},
+		{
+			name:   "unreachable",
+			apiURL: closed.URL,
+			want: []string{
+				"✗ valid",
+				"✗ management API",
+				"unreachable",
+			},
+		},
+		{
+			name: "malformed config file",
+			file: "token_file = ",
+			want: []string{
+				"✗ config file",
+				"! token            not resolved until the config file is fixed",
+			},
+			absent: []string{"✓ token", "✗ token", "Token\n"},
+		},
+		{
+			name: "config file found",
+			file: "token_file = \"/nonexistent\"\n",
+			ok:   true,
+			want: []string{"✓ config file", "(token_file)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{"SANITY_AUTH_TOKEN", "SANITY_ORG_ID", "SANITY_PROJECT_ID", "BLUEPRINTS_API_URL"} {
+				t.Setenv(k, "")
+			}
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if tt.file != "" {
+				if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			t.Setenv("BLUEPRINTS_TUI_CONFIG", path)
+
+			apiURL := tt.apiURL
+			if apiURL == "" {
+				apiURL = apiServer(t, tt.status).URL
+			}
+			var out strings.Builder
+			ok := Run(&out, config.Flags{Token: "sk-test-token", Project: "p1", APIURL: apiURL})
+			if ok != tt.ok {
+				t.Errorf("Run() = %v, want %v", ok, tt.ok)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(out.String(), s) {
+					t.Errorf("output missing %q:\n%s", s, out.String())
+				}
+			}
+			for _, s := range tt.absent {
+				if strings.Contains(out.String(), s) {
+					t.Errorf("output contains %q:\n%s", s, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Package history keeps local snapshots of resources under
+// ~/.config/blueprints-tui/history, one file per resource, so that past
+// parameters can be compared after the API only reports the current ones.
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+	"github.com/sanity-labs/blueprints-tui/internal/config"
+)
+
+// maxVersions bounds how many snapshots are kept per resource.
+const maxVersions = 50
+
+// Snapshot is a resource as it was after one operation.
+type Snapshot struct {
+	OperationID      string         `json:"operationId"`
+	Name             string         `json:"name"`
+	Type             string         `json:"type"`
+	Parameters       map[string]any `json:"parameters,omitempty"`
+	ProviderMetadata map[string]any `json:"providerMetadata,omitempty"`
+	UpdatedAt        time.Time      `json:"updatedAt"`
+	// FirstSeen is when this version was first fetched.
+	FirstSeen time.Time `json:"firstSeen"`
+}
+
+type file struct {
+	ResourceID string     `json:"resourceId"`
+	Versions   []Snapshot `json:"versions"`
+}
+
+// Store reads and writes snapshot files. It is safe for concurrent use.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// Open returns the store in the default location. The directory is
+// created on the first write.
+func Open() (*Store, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+	return &Store{dir: filepath.Join(dir, "history")}, nil
+}
+
+// path hashes the ID so that any ID is a safe file name.
+func (s *Store) path(resourceID string) string {
+	sum := sha256.Sum256([]byte(resourceID))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// Record stores r as the version for its OperationID, replacing an earlier
+// snapshot of the same operation. It reports whether anything changed.
+func (s *Store) Record(r api.Resource, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.read(r.ID)
+	if err != nil {
+		return false, err
+	}
+	snap := Snapshot{
+		OperationID:      r.OperationID,
+		Name:             r.Name,
+		Type:             r.Type,
+		Parameters:       r.Parameters,
+		ProviderMetadata: r.ProviderMetadata,
+		UpdatedAt:        r.UpdatedAt,
+		FirstSeen:        now,
+	}
+	// Round-trip through JSON so the comparison sees what was stored.
+	if data, err := json.Marshal(snap); err == nil {
+		_ = json.Unmarshal(data, &snap)
+	}
+
+	replaced := false
+	for i, v := range f.Versions {
+		if v.OperationID != r.OperationID {
+			continue
+		}
+		snap.FirstSeen = v.FirstSeen
+		if reflect.DeepEqual(v, snap) {
+			return false, nil
+		}
+		f.Versions[i] = snap
+		replaced = true
+		break
+	}
+	if !replaced {
+		f.Versions = append(f.Versions, snap)
+	}
+	sort.SliceStable(f.Versions, func(i, j int) bool {
+		return f.Versions[i].UpdatedAt.Before(f.Versions[j].UpdatedAt)
+	})
+	if len(f.Versions) > maxVersions {
+		f.Versions = f.Versions[len(f.Versions)-maxVersions:]
+	}
+	f.ResourceID = r.ID
+	return true, s.write(f)
+}
+
+// List returns the stored versions of a resource, oldest first.
+func (s *Store) List(resourceID string) ([]Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := s.read(resourceID)
+	return f.Versions, err
+}
+
+func (s *Store) read(resourceID string) (file, error) {
+	var f file
+	data, err := os.ReadFile(s.path(resourceID))
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return f, err
+	}
+	err = json.Unmarshal(data, &f)
+	return f, err
+}
+
+// write saves f atomically.
+func (s *Store) write(f file) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	p := s.path(f.ResourceID)
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
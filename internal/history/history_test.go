@@ -0,0 +1,129 @@
+package history
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+)
+
+var base = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func resource(op string, hour int, params map[string]any) api.Resource {
+	return api.Resource{
+		ID:          "res/with:odd chars",
+		Name:        "api-fn",
+		Type:        "sanity.function",
+		OperationID: op,
+		Parameters:  params,
+		UpdatedAt:   base.Add(time.Duration(hour) * time.Hour),
+	}
+}
+
+func ops(vs []Snapshot) []string {
+	var out []string
+	for _, v := range vs {
+		out = append(out, v.OperationID)
+	}
+	return out
+}
+
+func TestRecord(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s, err := Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := resource("op1", 0, map[string]any{"memory": 128})
+
+	if vs, err := s.List(r.ID); err != nil || len(vs) != 0 {
+		t.Fatalf("List() before any record = %v, %v", vs, err)
+	}
+
+	steps := []struct {
+		name    string
+		r       api.Resource
+		changed bool
+		want    []string
+	}{
+		{"first version", r, true, []string{"op1"}},
+		{"same version again", r, false, []string{"op1"}},
+		{"new operation", resource("op2", 2, map[string]any{"memory": 256}), true, []string{"op1", "op2"}},
+		{"older operation sorts first", resource("op0", -1, nil), true, []string{"op0", "op1", "op2"}},
+		{"same operation, new parameters", resource("op2", 2, map[string]any{"memory": 512}), true, []string{"op0", "op1", "op2"}},
+	}
+	for _, st := range steps {
+		changed, err := s.Record(st.r, base)
+		if err != nil {
+			t.Fatalf("%s: Record() = %v", st.name, err)
+		}
+		if changed != st.changed {
+			t.Errorf("%s: Record() changed = %v, want %v", st.name, changed, st.changed)
+		}
+		vs, err := s.List(r.ID)
+		if err != nil {
+			t.Fatalf("%s: List() = %v", st.name, err)
+		}
+		if got := fmt.Sprint(ops(vs)); got != fmt.Sprint(st.want) {
+			t.Errorf("%s: versions = %s, want %v", st.name, got, st.want)
+		}
+	}
+
+	vs, _ := s.List(r.ID)
+	if got := vs[2].Parameters["memory"]; got != float64(512) {
+		t.Errorf("replaced parameters = %v, want 512", got)
+	}
+}
+
+func TestRecordKeepsFirstSeen(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s, _ := Open()
+
+	s.Record(resource("op1", 0, map[string]any{"a": 1}), base)
+	s.Record(resource("op1", 0, map[string]any{"a": 2}), base.Add(time.Hour))
+	vs, _ := s.List("res/with:odd chars")
+	if len(vs) != 1 || !vs[0].FirstSeen.Equal(base) {
+		t.Errorf("FirstSeen = %v, want %v", vs[0].FirstSeen, base)
+	}
+}
+
+func TestRecordTrims(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s, _ := Open()
+
+	for i := range maxVersions + 5 {
+		if _, err := s.Record(resource(fmt.Sprintf("op%d", i), i, nil), base); err != nil {
+			t.Fatal(err)
+		}
+	}
+	vs, err := s.List("res/with:odd chars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs) != maxVersions {
+		t.Fatalf("len(versions) = %d, want %d", len(vs), maxVersions)
+	}
+	if vs[0].OperationID != "op5" || vs[len(vs)-1].OperationID != fmt.Sprintf("op%d", maxVersions+4) {
+		t.Errorf("kept %s…%s, want the newest %d", vs[0].OperationID, vs[len(vs)-1].OperationID, maxVersions)
+	}
+}
+
+func TestLoadAcrossStores(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a, _ := Open()
+	a.Record(resource("op1", 0, map[string]any{"memory": 128}), base)
+	a.Record(resource("op1", 0, nil), base)
+
+	b, _ := Open()
+	vs, err := b.List("res/with:odd chars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs) != 1 || vs[0].OperationID != "op1" || vs[0].Name != "api-fn" {
+		t.Errorf("List() from a new store = %+v", vs)
+	}
+	if vs, _ := b.List("other"); len(vs) != 0 {
+		t.Errorf("List() of an unknown resource = %+v", vs)
+	}
+}
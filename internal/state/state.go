@@ -0,0 +1,148 @@
+// Package state persists what the TUI remembers between launches in
+// ~/.config/blueprints-tui/state.json.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sanity-labs/blueprints-tui/internal/config"
+)
+
+// maxRecent bounds the most-recently-used scope list.
+const maxRecent = 8
+
+type Scope struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+func (s Scope) same(o Scope) bool {
+	return s.Type == o.Type && s.ID == o.ID
+}
+
+// State is the persisted data. Mutators always build new slices, so a
+// copy taken with Snapshot can be saved from another goroutine.
+type State struct {
+	LastScope  *Scope   `json:"lastScope,omitempty"`
+	Recent     []Scope  `json:"recentScopes,omitempty"`
+	Favourites []Scope  `json:"favourites,omitempty"`
+	Session    *Session `json:"session,omitempty"`
+
+	path string
+}
+
+// Session is the view stack the TUI showed when it last quit.
+type Session struct {
+	Scope           Scope  `json:"scope"`
+	StackFilter     string `json:"stackFilter,omitempty"`
+	SelectedStackID string `json:"selectedStackId,omitempty"`
+	// Layout is "list" or "table"; empty keeps the configured layout.
+	Layout          string `json:"layout,omitempty"`
+	Grouped         bool   `json:"grouped,omitempty"`
+	AllProjects     bool   `json:"allProjects,omitempty"`
+	StackID         string `json:"stackId,omitempty"`
+	Tab             int    `json:"tab,omitempty"`
+	ResourceCursor  int    `json:"resourceCursor,omitempty"`
+	OperationCursor int    `json:"operationCursor,omitempty"`
+	ResourceID      string `json:"resourceId,omitempty"`
+	OperationID     string `json:"operationId,omitempty"`
+}
+
+// Load reads the state file. A missing or unreadable file yields an empty
+// State that will still be saved to the default location.
+func Load() (*State, error) {
+	s := &State{}
+	dir, err := config.Dir()
+	if err != nil {
+		return s, err
+	}
+	s.path = filepath.Join(dir, "state.json")
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// Snapshot returns a copy that is safe to Save while s keeps changing.
+func (s *State) Snapshot() State {
+	return *s
+}
+
+// saveMu serialises saves, which run in background commands and share
+// the temporary file.
+var saveMu sync.Mutex
+
+// Save writes the state atomically. It is safe to call concurrently.
+func (s State) Save() error {
+	if s.path == "" {
+		return nil
+	}
+	saveMu.Lock()
+	defer saveMu.Unlock()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// UseScope records sc as the last scope and moves it to the front of the
+// recent list.
+func (s *State) UseScope(sc Scope) {
+	s.LastScope = &sc
+	recent := []Scope{sc}
+	for _, r := range s.Recent {
+		if !r.same(sc) && len(recent) < maxRecent {
+			recent = append(recent, r)
+		}
+	}
+	s.Recent = recent
+}
+
+func (s *State) IsFavourite(scopeType, id string) bool {
+	for _, f := range s.Favourites {
+		if f.Type == scopeType && f.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ToggleFavourite stars or unstars sc and reports whether it is now starred.
+func (s *State) ToggleFavourite(sc Scope) bool {
+	var favs []Scope
+	removed := false
+	for _, f := range s.Favourites {
+		if f.same(sc) {
+			removed = true
+			continue
+		}
+		favs = append(favs, f)
+	}
+	if !removed {
+		favs = append(favs, sc)
+	}
+	s.Favourites = favs
+	return !removed
+}
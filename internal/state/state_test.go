@@ -0,0 +1,124 @@
+package state
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func scope(id string) Scope {
+	return Scope{Type: "project", ID: id, Label: "Project " + id}
+}
+
+func ids(scopes []Scope) []string {
+	var out []string
+	for _, s := range scopes {
+		out = append(out, s.ID)
+	}
+	return out
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	s, err := Load()
+	if err != nil {
+		t.Fatalf("Load() on a missing file: %v", err)
+	}
+	s.UseScope(scope("p1"))
+	s.ToggleFavourite(Scope{Type: "organization", ID: "o1", Label: "Acme"})
+	s.Session = &Session{Scope: scope("p1"), StackID: "st1", Tab: 2, ResourceID: "r1"}
+	if err := s.Snapshot().Save(); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+
+	got, err := Load()
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	if !reflect.DeepEqual(got, s) {
+		t.Errorf("Load() = %+v, want %+v", got, s)
+	}
+}
+
+func TestConcurrentSaves(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s, _ := Load()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		s.UseScope(scope(fmt.Sprint(i)))
+		snap := s.Snapshot()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := snap.Save(); err != nil {
+				t.Errorf("Save() = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := Load(); err != nil {
+		t.Fatalf("Load() after concurrent saves: %v", err)
+	}
+}
+
+func TestUseScope(t *testing.T) {
+	var s State
+	for _, id := range []string{"a", "b", "c", "b"} {
+		s.UseScope(scope(id))
+	}
+	if s.LastScope == nil || s.LastScope.ID != "b" {
+		t.Errorf("LastScope = %+v, want b", s.LastScope)
+	}
+	if got, want := ids(s.Recent), []string{"b", "c", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Recent = %v, want %v", got, want)
+	}
+
+	for i := range maxRecent + 3 {
+		s.UseScope(scope(fmt.Sprint(i)))
+	}
+	if len(s.Recent) != maxRecent {
+		t.Errorf("len(Recent) = %d, want %d", len(s.Recent), maxRecent)
+	}
+	if s.Recent[0].ID != fmt.Sprint(maxRecent+2) {
+		t.Errorf("Recent[0] = %s, want the last scope used", s.Recent[0].ID)
+	}
+}
+
+func TestUseScopeKeepsSnapshot(t *testing.T) {
+	var s State
+	s.UseScope(scope("a"))
+	snap := s.Snapshot()
+	s.UseScope(scope("b"))
+	if got := ids(snap.Recent); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("snapshot Recent = %v, want [a]", got)
+	}
+}
+
+func TestToggleFavourite(t *testing.T) {
+	var s State
+	if !s.ToggleFavourite(scope("a")) {
+		t.Error("first toggle did not star")
+	}
+	s.ToggleFavourite(scope("b"))
+	if !s.IsFavourite("project", "a") || !s.IsFavourite("project", "b") {
+		t.Errorf("Favourites = %v, want a and b", ids(s.Favourites))
+	}
+	if s.IsFavourite("organization", "a") {
+		t.Error("IsFavourite matched a different scope type")
+	}
+
+	snap := s.Snapshot()
+	if s.ToggleFavourite(scope("a")) {
+		t.Error("second toggle did not unstar")
+	}
+	if got, want := ids(s.Favourites), []string{"b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Favourites = %v, want %v", got, want)
+	}
+	if got, want := ids(snap.Favourites), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("snapshot Favourites = %v, want %v", got, want)
+	}
+}
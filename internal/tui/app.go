@@ -7,6 +7,10 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/sanity-labs/blueprints-tui/internal/api"
+	"github.com/sanity-labs/blueprints-tui/internal/config"
+	"github.com/sanity-labs/blueprints-tui/internal/deeplink"
+	"github.com/sanity-labs/blueprints-tui/internal/history"
+	"github.com/sanity-labs/blueprints-tui/internal/state"
 	"strings"
 )
 
@@ -22,13 +26,54 @@ const (
 	routeStackDetail
 	routeResourceDetail
 	routeOperationDetail
+	routeLogin
+	routeDashboard
+	routeFailures
+	routeOpCompare
+	routeStackPicker
+	routeStackCompare
+	routeBlueprint
 )
 
+// Options configures how the TUI starts.
+type Options struct {
+	// HasScope skips the scope picker and opens the stack list directly.
+	HasScope bool
+	// LoginStore, when set, opens the login screen first. The token it
+	// obtains is saved to the store before continuing.
+	LoginStore config.TokenStore
+	// ReloadToken re-reads the configured token sources. The re-auth
+	// prompt offers it when the API rejects the current token.
+	ReloadToken func() (token, source string, err error)
+	// State is loaded from and saved back to disk; nil disables persistence.
+	State *state.State
+	// ResumeScope opens the stack list in this scope when HasScope is
+	// false, with the scope picker one esc away.
+	ResumeScope *state.Scope
+	// Session reopens the view stack saved on the last quit. Its scope is
+	// used like ResumeScope.
+	Session *state.Session
+	// Link opens the stack, resource or operation it points at. It takes
+	// precedence over ResumeScope and Session; when it carries no scope,
+	// or no stack, both are looked up.
+	Link *deeplink.Link
+	// Environments are other API endpoints whose stacks can be compared
+	// with the open one, in addition to the current endpoint.
+	Environments []Environment
+	// History records resources as they are fetched and backs the
+	// resource History view; nil disables both.
+	History *history.Store
+	// StackList sets the stack list's initial layout and table columns.
+	StackList config.StackList
+}
+
 type Model struct {
 	client *api.Client
 	styles styles
+	dark   bool
 	nav    []route
 
+	login           loginModel
 	scopePicker     scopePickerModel
 	scopeLabel      string
 	scopeType       string
@@ -36,28 +81,139 @@ type Model struct {
 	stackDetail     stackDetailModel
 	resourceDetail  resourceDetailModel
 	operationDetail operationDetailModel
+	dashboard       dashboardModel
+	failures        failuresModel
+	opCompare       opCompareModel
+	stackPicker     stackPickerModel
+	stackCompare    stackCompareModel
+	blueprint       blueprintModel
+	// dashboards numbers the dashboards opened, so ticks still arriving
+	// for one that was closed are not taken by the one open now.
+	dashboards int
 
-	help     help.Model
-	showHelp bool
-	width    int
-	height   int
+	reauth      reauthModel
+	reauthing   bool
+	switcher    scopeSwitcherModel
+	switching   bool
+	search      searchModel
+	searching   bool
+	palette     paletteModel
+	paletteOpen bool
+	reloadToken func() (string, string, error)
+
+	hasScope     bool
+	environments []Environment
+	history      *history.Store
+	stackLayout  config.StackList
+	searchIndex  searchIndex
+	crawl        searchCrawl
+	state        *state.State
+	restore      *restoreTarget
+	resolve      bool
+	notice       string
+	help         help.Model
+	showHelp     bool
+	width        int
+	height       int
 }
 
-func NewModel(client *api.Client, hasScope bool) Model {
+func NewModel(client *api.Client, opts Options) Model {
 	s := newStyles(true)
 	m := Model{
-		client: client,
-		styles: s,
-		help:   help.New(),
+		client:       client,
+		styles:       s,
+		dark:         true,
+		help:         help.New(),
+		hasScope:     opts.HasScope,
+		environments: opts.Environments,
+		history:      opts.History,
+		stackLayout:  opts.StackList,
+		reloadToken:  opts.ReloadToken,
+		state:        opts.State,
+	}
+	if opts.LoginStore != nil {
+		m.nav = []route{routeLogin}
+		m.login = newLoginModel(client.APIURL(), opts.LoginStore, s)
+		return m
+	}
+	m.startNav()
+	if opts.Link != nil {
+		m.restore = targetFromLink(*opts.Link)
+		if !m.hasScope || m.restore.stackID == "" {
+			m.resolve = true
+			m.setNotice("Looking up " + linkSubject(*m.restore) + "…")
+		}
+		return m
+	}
+	resume := opts.ResumeScope
+	if opts.Session != nil {
+		resume = &opts.Session.Scope
+		m.restore = targetFromSession(*opts.Session)
+	}
+	if !m.hasScope && resume != nil {
+		m.resumeScope(*resume)
 	}
-	if hasScope {
+	if m.restore != nil {
+		m.stackList.restoreView(m.restore.stackList)
+		if m.restore.stackID == "" {
+			// The stack list was all there was to reopen.
+			m.restore = nil
+		}
+	}
+	return m
+}
+
+// resumeScope opens the stack list in sc on top of the scope picker. The
+// picker is created but only fetched once the user backs into it.
+func (m *Model) resumeScope(sc state.Scope) {
+	m.scopeLabel = sc.Label
+	if m.scopeLabel == "" {
+		m.scopeLabel = sc.ID
+	}
+	m.scopeType = sc.Type
+	m.stackList = newStackListModel(m.client.WithScope(sc.Type, sc.ID), m.styles, m.stackLayout)
+	m.nav = append(m.nav, routeStackList)
+}
+
+// openScope pushes the stack list of the selected scope, recording the
+// scope as the most recent one. The list gets its own client, so requests
+// still in flight for the scope being left keep their scope.
+func (m *Model) openScope(msg scopeSelectedMsg) tea.Cmd {
+	m.scopeLabel = msg.label
+	m.scopeType = msg.scopeType
+	m.searchIndex = searchIndex{}
+	m.crawl = m.crawl.stop()
+	m.stackList = newStackListModel(m.client.WithScope(msg.scopeType, msg.scopeID), m.styles, m.stackLayout)
+	m.stackList.SetSize(m.effectiveWidth(), m.contentHeight())
+	m.nav = append(m.nav, routeStackList)
+	var save tea.Cmd
+	if m.state != nil {
+		m.state.UseScope(msg.stateScope())
+		save = m.saveState()
+	}
+	return tea.Batch(m.stackList.Init(), save)
+}
+
+// scoped returns the client of the open scope, which is the stack list's.
+// m.client itself only carries the scope given on the command line.
+func (m Model) scoped() *api.Client {
+	if m.stackList.client != nil {
+		return m.stackList.client
+	}
+	return m.client
+}
+
+// startNav sets up the first browsing route: the stack list when a scope
+// was given on startup, otherwise the scope picker.
+func (m *Model) startNav() {
+	if m.hasScope {
 		m.nav = []route{routeStackList}
-		m.stackList = newStackListModel(client, s)
+		m.scopeType, m.scopeLabel = m.client.Scope()
+		m.stackList = newStackListModel(m.client, m.styles, m.stackLayout)
 	} else {
 		m.nav = []route{routeScopePicker}
-		m.scopePicker = newScopePickerModel(client, s)
+		m.scopePicker = newScopePickerModel(m.client, m.state, m.styles)
 	}
-	return m
 }
 
 func (m Model) currentRoute() route {
@@ -68,20 +224,31 @@ func (m Model) currentRoute() route {
 }
 
 func (m Model) Init() tea.Cmd {
-	cmds := []tea.Cmd{tea.RequestBackgroundColor}
+	cmds := []tea.Cmd{tea.RequestBackgroundColor, waitForAuthRequired(m.client)}
+	if m.resolve {
+		scopeType, scopeID := m.client.Scope()
+		cmds = append(cmds, resolveLink(m.client, *m.restore, scopeType, scopeID))
+	}
 	switch m.currentRoute() {
+	case routeLogin:
+		cmds = append(cmds, m.login.Init())
 	case routeScopePicker:
 		cmds = append(cmds, m.scopePicker.Init())
 	default:
 		cmds = append(cmds, m.stackList.Init())
+		if !m.resolve && m.restore != nil {
+			cmds = append(cmds, m.fetchRestoreStack(m.restore.stackID))
+		}
 	}
 	return tea.Batch(cmds...)
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m.indexForSearch(msg)
 	switch msg := msg.(type) {
 	case tea.BackgroundColorMsg:
-		m.styles = newStyles(msg.IsDark())
+		m.dark = msg.IsDark()
+		m.styles = newStyles(m.dark)
 		m.updateChildStyles()
 		return m, nil
 
@@ -90,26 +257,198 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 		m.help.SetWidth(msg.Width)
 		m.resizeCurrentView()
+		if m.currentRoute() == routeScopePicker {
+			// A taller list shows projects that have not been counted.
+			return m, m.scopePicker.countOnScreen()
+		}
 		return m, nil
 
-	case scopeSelectedMsg:
-		m.scopeLabel = msg.label
-		m.scopeType = msg.scopeType
-		m.client.SetScope(msg.scopeType, msg.scopeID)
-		m.stackList = newStackListModel(m.client, m.styles)
-		m.stackList.SetSize(m.effectiveWidth(), m.contentHeight())
-		m.nav = append(m.nav, routeStackList)
+	case authRequiredMsg:
+		m.reauth = newReauthModel(m.client, m.reloadToken, m.styles)
+		m.reauthing = true
+		return m, waitForAuthRequired(m.client)
+
+	case tokenReloadedMsg, reauthErrMsg:
+		return m.updateReauth(msg)
+
+	case restoreStackMsg, restoreResourceMsg, restoreOperationMsg, restoreFailedMsg:
+		return m.updateRestore(msg)
+
+	case linkResolvedMsg, linkFailedMsg:
+		return m.updateLink(msg)
+
+	case orgsLoadedMsg, projectsLoadedMsg, stackCountMsg:
+		// The picker keeps loading underneath a scope opened from a link.
+		if m.hasRoute(routeScopePicker) {
+			var cmd tea.Cmd
+			m.scopePicker, cmd = m.scopePicker.Update(msg)
+			return m, cmd
+		}
+
+	case stacksLoadedMsg, projectStacksLoadedMsg:
+		if m.hasRoute(routeStackList) {
+			var cmd tea.Cmd
+			m.stackList, cmd = m.stackList.Update(msg)
+			return m, cmd
+		}
+
+	case dashboardLoadedMsg, dashboardTickMsg:
+		if m.hasRoute(routeDashboard) {
+			var cmd tea.Cmd
+			m.dashboard, cmd = m.dashboard.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case blueprintLoadedMsg:
+		if m.hasRoute(routeBlueprint) {
+			var cmd tea.Cmd
+			m.blueprint, cmd = m.blueprint.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case failuresLoadedMsg:
+		if m.hasRoute(routeFailures) {
+			var cmd tea.Cmd
+			m.failures, cmd = m.failures.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case resourcesLoadedMsg:
+		record := recordResources(m.history, m.stackDetail.client, msg.resources...)
+		if m.hasRoute(routeStackDetail) {
+			var cmd tea.Cmd
+			m.stackDetail, cmd = m.stackDetail.Update(msg)
+			return m, tea.Batch(record, cmd)
+		}
+		return m, record
+
+	case resourceLoadedMsg:
+		record := recordResources(m.history, m.resourceDetail.client, msg.resource)
+		var cmd tea.Cmd
+		m, cmd = m.updateCurrentView(msg)
+		return m, tea.Batch(record, cmd)
+
+	case stackLoadedMsg, operationsLoadedMsg, logsLoadedMsg:
+		// Stack detail data can land after a child view was pushed on top
+		// of it (e.g. while restoring a session); deliver it to its owner.
+		if m.hasRoute(routeStackDetail) {
+			var cmd tea.Cmd
+			m.stackDetail, cmd = m.stackDetail.Update(msg)
+			return m, cmd
+		}
+
+	case loginSucceededMsg:
+		m.client.SetToken(msg.token)
+		m.startNav()
+		m.resizeCurrentView()
+		if m.currentRoute() == routeScopePicker {
+			return m, m.scopePicker.Init()
+		}
 		return m, m.stackList.Init()
 
+	case scopeSelectedMsg:
+		return m, m.openScope(msg)
+
+	case stackComparePickedMsg:
+		if m.currentRoute() != routeStackPicker {
+			return m, nil
+		}
+		a := compareSide{
+			env: m.stackPicker.envs[0].Name,
+			ref: stackRef{stack: m.stackDetail.displayStack(), client: m.stackDetail.client},
+		}
+		if m.stackDetail.client == m.scoped() {
+			a.ref.project = m.scopeLabel
+		}
+		m.stackCompare = newStackCompareModel(a, msg.other, m.styles, m.effectiveWidth(), m.contentHeight())
+		m.nav[len(m.nav)-1] = routeStackCompare
+		return m, m.stackCompare.Init()
+
+	case scopeSwitchedMsg:
+		// Replace whatever scope is open, keeping the picker at the root
+		// if there is one.
+		m.restore = nil
+		if len(m.nav) > 0 && m.nav[0] == routeScopePicker {
+			m.nav = m.nav[:1]
+		} else {
+			m.nav = nil
+		}
+		return m, m.openScope(msg.scope)
+
+	case switcherDataMsg:
+		if m.switching {
+			return m.updateSwitcher(msg)
+		}
+		return m, nil
+
+	case crawlStacksMsg, crawlResourcesMsg, crawlDoneMsg:
+		return m.updateCrawl(msg)
+
+	case searchPickedMsg:
+		return m, fetchSearchStack(msg.entry)
+
+	case searchResultStackMsg:
+		if msg.err != nil {
+			m.setNotice("Opening " + msg.entry.title() + " failed: " + msg.err.Error())
+			return m, nil
+		}
+		e := msg.entry
+		e.stack = msg.stack
+		return m, m.openSearchResult(e)
+
+	case paletteCommandMsg:
+		return m.runCommand(msg.index)
+
+	case logsExportedMsg:
+		if msg.err != nil {
+			m.setNotice("Exporting logs failed: " + msg.err.Error())
+		} else {
+			m.setNotice("Exported " + countNoun(msg.n, "log line", "log lines") + " to " + msg.path + ".")
+		}
+		return m, nil
+
+	case tea.PasteMsg, tea.PasteStartMsg, tea.PasteEndMsg, tea.KeyReleaseMsg, tea.MouseMsg:
+		if m.overlayOpen() {
+			return m.updateOverlay(msg)
+		}
+
 	case tea.KeyPressMsg:
-		if key.Matches(msg, appKeys.Quit) && !m.isFiltering() {
-			return m, tea.Quit
+		if m.overlayOpen() {
+			if matchesWithoutText(msg, appKeys.Quit) {
+				return m, tea.Sequence(m.saveSession(), tea.Quit)
+			}
+			return m.updateOverlay(msg)
+		}
+		if m.notice != "" {
+			m.setNotice("")
+		}
+		if key.Matches(msg, appKeys.Quit) && (!m.isFiltering() || matchesWithoutText(msg, appKeys.Quit)) {
+			return m, tea.Sequence(m.saveSession(), tea.Quit)
 		}
 		if key.Matches(msg, appKeys.Help) {
 			m.showHelp = !m.showHelp
 			m.resizeCurrentView()
 			return m, nil
 		}
+		if key.Matches(msg, appKeys.SwitchScope) && !m.isFiltering() && m.currentRoute() != routeLogin {
+			return m.openSwitcher()
+		}
+		if key.Matches(msg, appKeys.Search) && !m.isFiltering() && m.currentRoute() != routeLogin {
+			var crawl tea.Cmd
+			// Crawl once per scope and mode, again if listing failed.
+			if m.hasRoute(routeStackList) && (!m.crawl.covers(m.scoped(), m.stackList.allProjects) || m.crawl.err != nil) {
+				crawl = m.startCrawl()
+			}
+			m.search = newSearchModel(m.searchIndex, m.crawl.status(), m.styles, m.effectiveWidth())
+			m.searching = true
+			return m, crawl
+		}
+		if key.Matches(msg, appKeys.Palette) && !m.isFiltering() {
+			return m.openPalette()
+		}
 		if nav, cmd, handled := m.handleNavigation(msg); handled {
 			return nav, cmd
 		}
@@ -131,6 +470,8 @@ func (m Model) View() tea.View {
 
 	var content string
 	switch m.currentRoute() {
+	case routeLogin:
+		content = m.login.View()
 	case routeScopePicker:
 		content = m.scopePicker.View()
 	case routeStackList:
@@ -141,6 +482,31 @@ func (m Model) View() tea.View {
 		content = m.resourceDetail.View()
 	case routeOperationDetail:
 		content = m.operationDetail.View()
+	case routeDashboard:
+		content = m.dashboard.View()
+	case routeFailures:
+		content = m.failures.View()
+	case routeOpCompare:
+		content = m.opCompare.View()
+	case routeStackPicker:
+		content = m.stackPicker.View()
+	case routeStackCompare:
+		content = m.stackCompare.View()
+	case routeBlueprint:
+		content = m.blueprint.View()
+	}
+
+	if m.switching {
+		content = overlay(content, m.switcher.View(), m.effectiveWidth(), m.contentHeight())
+	}
+	if m.searching {
+		content = overlay(content, m.search.View(), m.effectiveWidth(), m.contentHeight())
+	}
+	if m.paletteOpen {
+		content = overlay(content, m.palette.View(), m.effectiveWidth(), m.contentHeight())
+	}
+	if m.reauthing {
+		content = overlay(content, m.reauth.View(), m.effectiveWidth(), m.contentHeight())
 	}
 
 	v := tea.NewView(header + "\n\n" + content + "\n" + footer)
@@ -148,15 +514,143 @@ func (m Model) View() tea.View {
 	return v
 }
 
-// footerView returns the status bar, optionally preceded by expanded help.
+// saveState writes a snapshot of the persisted state in the background.
+func (m Model) saveState() tea.Cmd {
+	if m.state == nil {
+		return nil
+	}
+	snap := m.state.Snapshot()
+	client := m.client
+	return func() tea.Msg {
+		if err := snap.Save(); err != nil {
+			client.Debugf("saving state: %s", err)
+		}
+		return nil
+	}
+}
+
+// overlayOpen reports whether a modal is drawn over the current view.
+func (m Model) overlayOpen() bool {
+	return m.reauthing || m.switching || m.searching || m.paletteOpen
+}
+
+// updateOverlay hands user input to the open modal, topmost first, so
+// neither key presses nor pastes reach the view underneath.
+func (m Model) updateOverlay(msg tea.Msg) (Model, tea.Cmd) {
+	switch {
+	case m.reauthing:
+		return m.updateReauth(msg)
+	case m.switching:
+		return m.updateSwitcher(msg)
+	case m.searching:
+		return m.updateSearch(msg)
+	default:
+		return m.updatePalette(msg)
+	}
+}
+
+func (m Model) updateReauth(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	var done bool
+	m.reauth, cmd, done = m.reauth.Update(msg)
+	if done {
+		m.reauthing = false
+	}
+	return m, cmd
+}
+
+// openOperation pushes the stack detail of ref's stack on its Operations
+// tab, then the operation itself, so esc walks back through the stack.
+func (m *Model) openOperation(ref opRef) tea.Cmd {
+	w, h := m.effectiveWidth(), m.contentHeight()
+	m.stackDetail = newStackDetailModel(ref.stack.client, ref.stack.stack, m.styles, w, h)
+	tabCmd := m.stackDetail.restoreView(tabOperations, 0, 0)
+	m.operationDetail = newOperationDetailModel(ref.stack.client, ref.stack.stack.ID, ref.op, m.styles, w, h)
+	m.nav = append(m.nav, routeStackDetail, routeOperationDetail)
+	return tea.Batch(m.stackDetail.Init(), tabCmd, m.operationDetail.Init())
+}
+
+// openFailures pushes the failure triage view for the open scope.
+func (m *Model) openFailures(allProjects bool) tea.Cmd {
+	m.failures = newFailuresModel(m.scoped(), allProjects, m.styles, m.effectiveWidth(), m.contentHeight())
+	m.nav = append(m.nav, routeFailures)
+	return m.failures.Init()
+}
+
+// compareEnvironments lists the endpoints stacks can be compared across,
+// the current one first.
+func (m Model) compareEnvironments() []Environment {
+	client := m.scoped()
+	current := Environment{
+		Name:    envHost(client.APIURL()) + " (current)",
+		APIURL:  client.APIURL(),
+		Connect: func() (*api.Client, error) { return client, nil },
+	}
+	return append([]Environment{current}, m.environments...)
+}
+
+// openSwitcher shows the scope switcher over the current route, reusing
+// the picker's organizations and projects when it has loaded them.
+func (m Model) openSwitcher() (Model, tea.Cmd) {
+	m.switcher = newScopeSwitcherModel(m.scoped(), m.state, m.styles, m.effectiveWidth())
+	if p := m.scopePicker; p.client != nil && !p.loading && p.err == nil && p.projectsLoaded {
+		m.switcher = m.switcher.withData(p.orgs, p.projects)
+	}
+	m.switching = true
+	return m, m.switcher.Init()
+}
+
+func (m Model) updateSearch(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	var done bool
+	m.search, cmd, done = m.search.Update(msg)
+	if done {
+		m.searching = false
+	}
+	return m, cmd
+}
+
+func (m Model) updateSwitcher(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	var done bool
+	m.switcher, cmd, done = m.switcher.Update(msg)
+	if done {
+		m.switching = false
+	}
+	return m, cmd
+}
+
+// footerView returns the status bar, optionally preceded by expanded help
+// and a one-off notice.
 func (m Model) footerView() string {
 	status := m.statusBar()
+	if m.notice != "" {
+		status = m.styles.logWarn.Render(m.notice) + "\n" + status
+	}
 	if m.showHelp {
-		return m.styles.help.Render(m.help.View(appKeys)) + "\n" + status
+		return m.styles.help.Render(m.fullHelp()) + "\n" + status
 	}
 	return status
 }
 
+// fullHelp lists every action, in as few rows as fit the width.
+func (m Model) fullHelp() string {
+	h := m.help
+	h.SetWidth(0)
+	for rows := minHelpRows; ; rows++ {
+		v := h.FullHelpView(helpColumns(rows))
+		if rows >= len(keyActions) || lipgloss.Width(v) <= m.help.Width() {
+			return v
+		}
+	}
+}
+
+// setNotice shows text above the status bar until the next key press.
+func (m *Model) setNotice(text string) {
+	m.notice = text
+	m.resizeCurrentView()
+}
+
 func (m Model) headerBox() string {
 	s := m.styles
 	title := s.headerTitle.Render("Blueprints")
@@ -166,10 +660,21 @@ func (m Model) headerBox() string {
 	c := title
 
 	switch m.currentRoute() {
+	case routeLogin:
+		c += sep + s.headerHint.Render("Log in")
 	case routeScopePicker:
 		c += sep + s.headerHint.Render("Select a scope")
 	case routeStackList:
 		c += sep + s.headerValue.Render(m.scopeLabel)
+	case routeDashboard:
+		c += sep + s.headerHint.Render(m.scopeLabel) +
+			dot + s.headerValue.Render("Dashboard")
+	case routeFailures:
+		c += sep + s.headerHint.Render(m.scopeLabel) +
+			dot + s.headerValue.Render("Failures")
+	case routeBlueprint:
+		c += sep + s.headerHint.Render(m.scopeLabel) +
+			dot + s.headerValue.Render("Blueprint "+m.blueprint.blueprintID)
 	case routeStackDetail:
 		c += sep + s.headerValue.Render(m.scopeLabel) +
 			dot + s.headerValue.Render(m.stackDetail.stack.Name)
@@ -181,27 +686,122 @@ func (m Model) headerBox() string {
 		c += sep + s.headerHint.Render(m.scopeLabel) +
 			dot + s.headerHint.Render(m.stackDetail.stack.Name) +
 			dot + s.headerValue.Render(m.operationDetail.operation.ID)
+	case routeOpCompare:
+		c += sep + s.headerHint.Render(m.scopeLabel) +
+			dot + s.headerHint.Render(m.stackDetail.stack.Name) +
+			dot + s.headerValue.Render(m.opCompare.a.ID+" ↔ "+m.opCompare.b.ID)
+	case routeStackPicker:
+		c += sep + s.headerHint.Render(m.scopeLabel) +
+			dot + s.headerHint.Render(m.stackDetail.stack.Name) +
+			dot + s.headerValue.Render("Compare")
+	case routeStackCompare:
+		c += sep + s.headerHint.Render(m.scopeLabel) +
+			dot + s.headerValue.Render(m.stackCompare.a.ref.stack.Name+" ↔ "+m.stackCompare.b.ref.stack.Name)
 	}
 
 	return s.headerBox.Render(c)
 }
 
-func (m Model) helpItem(k, label string) string {
-	return m.styles.keycap.Render(k) + " " + m.styles.headerHint.Render(label)
+// helpItem renders a status bar hint for the keys bound to bindings, so
+// the hint follows the [keys] section of the config file.
+func (m Model) helpItem(label string, bindings ...key.Binding) string {
+	keys := make([]string, len(bindings))
+	for i, b := range bindings {
+		keys[i] = b.Help().Key
+		if _, ok := namedKeys[keys[i]]; ok {
+			keys[i] = strings.ToUpper(keys[i])
+		}
+	}
+	return m.styles.keycap.Render(strings.Join(keys, "/")) + " " + m.styles.headerHint.Render(label)
 }
 
 func (m Model) statusBar() string {
 	sep := m.styles.headerHint.Render("  ·  ")
 	var hints []string
 	switch m.currentRoute() {
+	case routeLogin:
+		hints = []string{m.helpItem("retry", appKeys.Refresh), m.helpItem("quit", appKeys.Quit)}
 	case routeScopePicker:
-		hints = []string{m.helpItem("ENTER", "select"), m.helpItem("/", "filter"), m.helpItem("?", "help"), m.helpItem("q", "quit")}
+		hints = []string{m.helpItem("select", appKeys.Select), m.helpItem("filter", appKeys.Filter), m.helpItem("expand", appKeys.Expand), m.helpItem("favourite", appKeys.Favourite), m.helpItem("help", appKeys.Help), m.helpItem("quit", appKeys.Quit)}
 	case routeStackList:
-		hints = []string{m.helpItem("ENTER", "select"), m.helpItem("/", "filter"), m.helpItem("r", "refresh"), m.helpItem("ESC", "back"), m.helpItem("?", "help"), m.helpItem("q", "quit")}
+		hints = []string{m.helpItem("select", appKeys.Select)}
+		if m.stackList.showsTable() {
+			hints = append(hints, m.helpItem("sort", appKeys.Sort, appKeys.ReverseSort))
+		} else if !m.stackList.allProjects && !m.stackList.grouped {
+			hints = append(hints, m.helpItem("filter", appKeys.Filter))
+		}
+		label := "group"
+		if m.stackList.grouped {
+			label = "ungroup"
+		}
+		layout := "table"
+		if m.stackList.tableLayout {
+			layout = "list"
+		}
+		hints = append(hints, m.helpItem(label, appKeys.GroupByBlueprint), m.helpItem(layout, appKeys.Layout), m.helpItem("refresh", appKeys.Refresh), m.helpItem("dashboard", appKeys.Dashboard), m.helpItem("failures", appKeys.Failures), m.helpItem("scope", appKeys.SwitchScope))
+		if m.stackList.canShowAllProjects() {
+			label := "all projects"
+			if m.stackList.allProjects {
+				label = "org stacks"
+			}
+			hints = append(hints, m.helpItem(label, appKeys.AllProjects))
+		}
+		hints = append(hints, m.helpItem("back", appKeys.Back), m.helpItem("help", appKeys.Help), m.helpItem("quit", appKeys.Quit))
 	case routeStackDetail:
-		hints = []string{m.helpItem("ENTER", "select"), m.helpItem("TAB", "tabs"), m.helpItem("r", "refresh"), m.helpItem("ESC", "back"), m.helpItem("?", "help"), m.helpItem("q", "quit")}
-	case routeResourceDetail, routeOperationDetail:
-		hints = []string{m.helpItem("ESC", "back"), m.helpItem("?", "help"), m.helpItem("q", "quit")}
+		if m.stackDetail.filtering() {
+			hints = []string{m.helpItem("apply filter", appKeys.Select), m.helpItem("clear filter", appKeys.Back)}
+			break
+		}
+		hints = []string{m.helpItem("select", appKeys.Select), m.helpItem("tabs", appKeys.Tab), m.helpItem("refresh", appKeys.Refresh), m.helpItem("compare stack", appKeys.CompareWith)}
+		if tab := m.stackDetail.activeTab; tab == tabResources || tab == tabOperations {
+			hints = append(hints, m.helpItem("filter", appKeys.Filter), m.helpItem("sort", appKeys.Sort, appKeys.ReverseSort))
+		}
+		if m.stackDetail.activeTab == tabResources {
+			label := "group by type"
+			if m.stackDetail.groupByType {
+				label = "ungroup"
+			}
+			hints = append(hints, m.helpItem(label, appKeys.GroupByType))
+		}
+		if m.stackDetail.activeTab == tabGraph {
+			hints = append(hints, m.helpItem("move", appKeys.Up, appKeys.Down))
+		}
+		if m.stackDetail.activeTab == tabOperations {
+			label := "timeline"
+			if m.stackDetail.opTimeline {
+				label = "table"
+			}
+			hints = append(hints, m.helpItem("status", appKeys.StatusFilter), m.helpItem(label, appKeys.Timeline), m.helpItem("mark", appKeys.Mark))
+			if len(m.stackDetail.marked) == 2 {
+				hints = append(hints, m.helpItem("compare", appKeys.Compare))
+			}
+		}
+		back := "back"
+		if m.stackDetail.hasFilter() {
+			back = "clear filter"
+		}
+		hints = append(hints, m.helpItem(back, appKeys.Back), m.helpItem("help", appKeys.Help), m.helpItem("quit", appKeys.Quit))
+	case routeStackPicker:
+		hints = []string{m.helpItem("select", appKeys.Select), m.helpItem("filter", appKeys.Filter), m.helpItem("back", appKeys.Back), m.helpItem("help", appKeys.Help), m.helpItem("quit", appKeys.Quit)}
+	case routeResourceDetail:
+		if m.resourceDetail.showHistory {
+			hints = []string{m.helpItem("version", appKeys.Up, appKeys.Down), m.helpItem("mark base", appKeys.Mark), m.helpItem("resource", appKeys.History)}
+		} else {
+			hints = []string{m.helpItem("history", appKeys.History)}
+		}
+		hints = append(hints, m.helpItem("back", appKeys.Back), m.helpItem("help", appKeys.Help), m.helpItem("quit", appKeys.Quit))
+	case routeOpCompare, routeStackCompare:
+		hints = []string{m.helpItem("back", appKeys.Back), m.helpItem("help", appKeys.Help), m.helpItem("quit", appKeys.Quit)}
+	case routeOperationDetail:
+		label := "timeline"
+		if m.operationDetail.showTimeline {
+			label = "logs"
+		}
+		hints = []string{m.helpItem(label, appKeys.Timeline), m.helpItem("back", appKeys.Back), m.helpItem("help", appKeys.Help), m.helpItem("quit", appKeys.Quit)}
+	case routeDashboard:
+		hints = []string{m.helpItem("open", appKeys.Select), m.helpItem("refresh", appKeys.Refresh), m.helpItem("failures", appKeys.Failures), m.helpItem("back", appKeys.Back), m.helpItem("help", appKeys.Help), m.helpItem("quit", appKeys.Quit)}
+	case routeFailures, routeBlueprint:
+		hints = []string{m.helpItem("open", appKeys.Select), m.helpItem("refresh", appKeys.Refresh), m.helpItem("back", appKeys.Back), m.helpItem("help", appKeys.Help), m.helpItem("quit", appKeys.Quit)}
 	}
 	return strings.Join(hints, sep)
 }
@@ -219,6 +819,15 @@ func (m Model) handleNavigation(msg tea.KeyPressMsg) (Model, tea.Cmd, bool) {
 				return m, func() tea.Msg { return scope }, true
 			}
 		}
+		if key.Matches(msg, appKeys.Favourite) {
+			if cmd, ok := m.scopePicker.toggleFavourite(); ok {
+				return m, tea.Batch(cmd, m.saveState()), true
+			}
+			return m, nil, true
+		}
+		if key.Matches(msg, appKeys.Expand) {
+			return m, m.scopePicker.toggleExpanded(), true
+		}
 
 	case routeStackList:
 		if m.isFiltering() {
@@ -227,11 +836,20 @@ func (m Model) handleNavigation(msg tea.KeyPressMsg) (Model, tea.Cmd, bool) {
 		if key.Matches(msg, appKeys.Back) && m.scopePicker.client != nil {
 			m.nav = m.nav[:len(m.nav)-1]
 			m.resizeCurrentView()
+			if m.scopePicker.loading {
+				// Resumed straight into a scope; the picker was never fetched.
+				return m, m.scopePicker.Init(), true
+			}
 			return m, nil, true
 		}
 		if key.Matches(msg, appKeys.Select) {
-			if stack, ok := m.stackList.selectedStack(); ok {
-				m.stackDetail = newStackDetailModel(m.client, stack, m.styles, m.effectiveWidth(), m.contentHeight())
+			if id, ok := m.stackList.selectedBlueprint(); ok {
+				m.blueprint = newBlueprintModel(m.scoped(), id, m.styles, m.effectiveWidth(), m.contentHeight())
+				m.nav = append(m.nav, routeBlueprint)
+				return m, m.blueprint.Init(), true
+			}
+			if stack, client, ok := m.stackList.selectedStack(); ok {
+				m.stackDetail = newStackDetailModel(client, stack, m.styles, m.effectiveWidth(), m.contentHeight())
 				m.nav = append(m.nav, routeStackDetail)
 				return m, m.stackDetail.Init(), true
 			}
@@ -241,8 +859,47 @@ func (m Model) handleNavigation(msg tea.KeyPressMsg) (Model, tea.Cmd, bool) {
 			m.stackList, cmd = m.stackList.Refresh()
 			return m, cmd, true
 		}
+		if key.Matches(msg, appKeys.Dashboard) {
+			m.dashboards++
+			m.dashboard = newDashboardModel(m.scoped(), m.dashboards, m.stackList.allProjects, m.styles, m.effectiveWidth(), m.contentHeight())
+			m.nav = append(m.nav, routeDashboard)
+			return m, m.dashboard.Init(), true
+		}
+		if key.Matches(msg, appKeys.Failures) {
+			return m, m.openFailures(m.stackList.allProjects), true
+		}
+		if key.Matches(msg, appKeys.GroupByBlueprint) {
+			m.stackList = m.stackList.toggleGrouped()
+			return m, nil, true
+		}
+		if key.Matches(msg, appKeys.Layout) {
+			m.stackList = m.stackList.toggleLayout()
+			return m, nil, true
+		}
+		if m.stackList.showsTable() {
+			switch {
+			case key.Matches(msg, appKeys.Sort):
+				m.stackList = m.stackList.cycleSort()
+				return m, nil, true
+			case key.Matches(msg, appKeys.ReverseSort):
+				m.stackList = m.stackList.reverseSort()
+				return m, nil, true
+			}
+		}
+		if key.Matches(msg, appKeys.AllProjects) && m.stackList.canShowAllProjects() {
+			var cmd tea.Cmd
+			m.stackList, cmd = m.stackList.toggleAllProjects()
+			return m, cmd, true
+		}
 
 	case routeStackDetail:
+		if m.stackDetail.filtering() {
+			break
+		}
+		if key.Matches(msg, appKeys.Back) && m.stackDetail.hasFilter() {
+			m.stackDetail.clearFilter()
+			return m, nil, true
+		}
 		if key.Matches(msg, appKeys.Back) {
 			m.nav = m.nav[:len(m.nav)-1]
 			m.resizeCurrentView()
@@ -251,34 +908,109 @@ func (m Model) handleNavigation(msg tea.KeyPressMsg) (Model, tea.Cmd, bool) {
 		if key.Matches(msg, appKeys.Select) {
 			w, h := m.effectiveWidth(), m.contentHeight()
 			switch m.stackDetail.activeTab {
-			case tabResources:
-				if r, ok := m.stackDetail.selectedResource(); ok {
-					m.resourceDetail = newResourceDetailModel(m.client, m.stackDetail.stack.ID, r, m.styles, w, h)
+			case tabResources, tabGraph:
+				r, ok := m.stackDetail.selectedResource()
+				if m.stackDetail.activeTab == tabGraph {
+					r, ok = m.stackDetail.selectedGraphResource()
+				}
+				if ok {
+					m.resourceDetail = newResourceDetailModel(m.stackDetail.client, m.stackDetail.stack.ID, r, m.styles, w, h)
 					m.nav = append(m.nav, routeResourceDetail)
 					return m, m.resourceDetail.Init(), true
 				}
 			case tabOperations:
 				if op, ok := m.stackDetail.selectedOperation(); ok {
-					m.operationDetail = newOperationDetailModel(m.client, m.stackDetail.stack.ID, op, m.styles, w, h)
+					m.operationDetail = newOperationDetailModel(m.stackDetail.client, m.stackDetail.stack.ID, op, m.styles, w, h)
 					m.nav = append(m.nav, routeOperationDetail)
 					return m, m.operationDetail.Init(), true
 				}
 			}
 		}
+		if key.Matches(msg, appKeys.CompareWith) {
+			m.stackPicker = newStackPickerModel(m.stackDetail.displayStack(), m.compareEnvironments(), m.styles, m.effectiveWidth(), m.contentHeight())
+			m.nav = append(m.nav, routeStackPicker)
+			return m, m.stackPicker.Init(), true
+		}
+		if key.Matches(msg, appKeys.Compare) && m.stackDetail.activeTab == tabOperations {
+			a, b, ok := m.stackDetail.markedOperations()
+			if !ok {
+				m.setNotice("Mark two operations with " + appKeys.Mark.Help().Key + " to compare them.")
+				return m, nil, true
+			}
+			m.opCompare = newOpCompareModel(m.stackDetail.client, m.stackDetail.stack.ID, a, b, m.styles, m.effectiveWidth(), m.contentHeight())
+			m.nav = append(m.nav, routeOpCompare)
+			return m, m.opCompare.Init(), true
+		}
 
 	case routeResourceDetail:
+		if key.Matches(msg, appKeys.History) || (key.Matches(msg, appKeys.Back) && m.resourceDetail.showHistory) {
+			return m, m.resourceDetail.toggleHistory(m.history), true
+		}
 		if key.Matches(msg, appKeys.Back) {
 			m.nav = m.nav[:len(m.nav)-1]
 			m.resizeCurrentView()
 			return m, nil, true
 		}
 
-	case routeOperationDetail:
+	case routeStackPicker:
+		if m.stackPicker.filtering() || !key.Matches(msg, appKeys.Back) {
+			break
+		}
+		if p, ok := m.stackPicker.back(); ok {
+			m.stackPicker = p
+			return m, nil, true
+		}
+		m.nav = m.nav[:len(m.nav)-1]
+		m.resizeCurrentView()
+		return m, nil, true
+
+	case routeOperationDetail, routeOpCompare, routeStackCompare:
 		if key.Matches(msg, appKeys.Back) {
 			m.nav = m.nav[:len(m.nav)-1]
 			m.resizeCurrentView()
 			return m, nil, true
 		}
+
+	case routeDashboard:
+		if key.Matches(msg, appKeys.Back) {
+			m.nav = m.nav[:len(m.nav)-1]
+			m.resizeCurrentView()
+			return m, nil, true
+		}
+		if key.Matches(msg, appKeys.Select) {
+			if ref, ok := m.dashboard.selected(); ok {
+				return m, m.openOperation(ref), true
+			}
+		}
+		if key.Matches(msg, appKeys.Failures) {
+			return m, m.openFailures(m.dashboard.allProjects), true
+		}
+
+	case routeBlueprint:
+		if key.Matches(msg, appKeys.Back) {
+			m.nav = m.nav[:len(m.nav)-1]
+			m.resizeCurrentView()
+			return m, nil, true
+		}
+		if key.Matches(msg, appKeys.Select) {
+			if ref, ok := m.blueprint.selected(); ok {
+				m.stackDetail = newStackDetailModel(ref.client, ref.stack, m.styles, m.effectiveWidth(), m.contentHeight())
+				m.nav = append(m.nav, routeStackDetail)
+				return m, m.stackDetail.Init(), true
+			}
+		}
+
+	case routeFailures:
+		if key.Matches(msg, appKeys.Back) {
+			m.nav = m.nav[:len(m.nav)-1]
+			m.resizeCurrentView()
+			return m, nil, true
+		}
+		if key.Matches(msg, appKeys.Select) {
+			if ref, ok := m.failures.selected(); ok {
+				return m, m.openOperation(ref), true
+			}
+		}
 	}
 
 	return m, nil, false
@@ -287,6 +1019,8 @@ func (m Model) handleNavigation(msg tea.KeyPressMsg) (Model, tea.Cmd, bool) {
 func (m Model) updateCurrentView(msg tea.Msg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch m.currentRoute() {
+	case routeLogin:
+		m.login, cmd = m.login.Update(msg)
 	case routeScopePicker:
 		m.scopePicker, cmd = m.scopePicker.Update(msg)
 	case routeStackList:
@@ -297,22 +1031,47 @@ func (m Model) updateCurrentView(msg tea.Msg) (Model, tea.Cmd) {
 		m.resourceDetail, cmd = m.resourceDetail.Update(msg)
 	case routeOperationDetail:
 		m.operationDetail, cmd = m.operationDetail.Update(msg)
+	case routeDashboard:
+		m.dashboard, cmd = m.dashboard.Update(msg)
+	case routeFailures:
+		m.failures, cmd = m.failures.Update(msg)
+	case routeOpCompare:
+		m.opCompare, cmd = m.opCompare.Update(msg)
+	case routeStackPicker:
+		m.stackPicker, cmd = m.stackPicker.Update(msg)
+	case routeStackCompare:
+		m.stackCompare, cmd = m.stackCompare.Update(msg)
+	case routeBlueprint:
+		m.blueprint, cmd = m.blueprint.Update(msg)
 	}
 	return m, cmd
 }
 
 func (m *Model) updateChildStyles() {
 	s := m.styles
+	m.login.styles = s
+	m.reauth.styles = s
+	m.switcher.styles = s
+	m.search.styles = s
+	m.palette.styles = s
 	m.scopePicker.styles = s
 	m.stackList.styles = s
 	m.stackDetail.styles = s
 	m.resourceDetail.styles = s
 	m.operationDetail.styles = s
+	m.dashboard.styles = s
+	m.failures.styles = s
+	m.opCompare.styles = s
+	m.stackPicker.styles = s
+	m.stackCompare.styles = s
+	m.blueprint.styles = s
 }
 
 func (m *Model) resizeCurrentView() {
 	w, h := m.effectiveWidth(), m.contentHeight()
 	switch m.currentRoute() {
+	case routeLogin:
+		m.login.SetSize(w, h)
 	case routeScopePicker:
 		m.scopePicker.SetSize(w, h)
 	case routeStackList:
@@ -323,6 +1082,18 @@ func (m *Model) resizeCurrentView() {
 		m.resourceDetail.SetSize(w, h)
 	case routeOperationDetail:
 		m.operationDetail.SetSize(w, h)
+	case routeDashboard:
+		m.dashboard.SetSize(w, h)
+	case routeFailures:
+		m.failures.SetSize(w, h)
+	case routeOpCompare:
+		m.opCompare.SetSize(w, h)
+	case routeStackPicker:
+		m.stackPicker.SetSize(w, h)
+	case routeStackCompare:
+		m.stackCompare.SetSize(w, h)
+	case routeBlueprint:
+		m.blueprint.SetSize(w, h)
 	}
 }
 
@@ -332,6 +1103,10 @@ func (m Model) isFiltering() bool {
 		return m.scopePicker.list.FilterState() == list.Filtering
 	case routeStackList:
 		return m.stackList.list.FilterState() == list.Filtering
+	case routeStackPicker:
+		return m.stackPicker.filtering()
+	case routeStackDetail:
+		return m.stackDetail.filtering()
 	}
 	return false
 }
@@ -0,0 +1,342 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/spinner"
+	"charm.land/bubbles/v2/table"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+)
+
+// noBlueprint labels the group of stacks without a blueprint ID.
+const noBlueprint = "(no blueprint)"
+
+// stackGroup is the stacks instantiated from one blueprint.
+type stackGroup struct {
+	blueprintID string
+	stacks      []stackRef
+}
+
+func (g stackGroup) label() string {
+	if g.blueprintID == "" {
+		return noBlueprint
+	}
+	return g.blueprintID
+}
+
+// groupByBlueprint groups stacks by BlueprintID, sorted by ID with stacks
+// without one last. Stacks keep their order within a group.
+func groupByBlueprint(refs []stackRef) []stackGroup {
+	index := make(map[string]int)
+	var groups []stackGroup
+	for _, ref := range refs {
+		id := ref.stack.BlueprintID
+		i, ok := index[id]
+		if !ok {
+			i = len(groups)
+			index[id] = i
+			groups = append(groups, stackGroup{blueprintID: id})
+		}
+		groups[i].stacks = append(groups[i].stacks, ref)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i].blueprintID, groups[j].blueprintID
+		if (a == "") != (b == "") {
+			return b == ""
+		}
+		return a < b
+	})
+	return groups
+}
+
+// groupStatus aggregates the latest operation of each stack into the
+// status shown for the group, the worst of them, and a count per outcome
+// such as "2 completed · 1 failed".
+func groupStatus(refs []stackRef) (status, summary string) {
+	var completed, failed, running, other, never int
+	for _, ref := range refs {
+		op := ref.stack.RecentOperation
+		switch {
+		case op == nil:
+			never++
+		case strings.EqualFold(op.Status, "FAILED"):
+			failed++
+		case isInProgress(op.Status):
+			running++
+		case strings.EqualFold(op.Status, "COMPLETED"), strings.EqualFold(op.Status, "SUCCESS"):
+			completed++
+		default:
+			other++
+		}
+	}
+	switch {
+	case failed > 0:
+		status = "FAILED"
+	case running > 0:
+		status = "IN_PROGRESS"
+	case completed > 0:
+		status = "COMPLETED"
+	}
+	var parts []string
+	for _, c := range []struct {
+		n     int
+		label string
+	}{
+		{completed, "completed"},
+		{failed, "failed"},
+		{running, "in progress"},
+		{other, "other"},
+		{never, "never deployed"},
+	} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.label))
+		}
+	}
+	return status, strings.Join(parts, " · ")
+}
+
+// lastOperation describes a stack's latest operation: "completed 3h ago".
+func lastOperation(st api.Stack, now time.Time) string {
+	op := st.RecentOperation
+	if op == nil {
+		return ""
+	}
+	return strings.ToLower(strings.ReplaceAll(op.Status, "_", " ")) + " " + formatAge(op.CreatedAt, now)
+}
+
+// listScopeStacks lists every stack in the open scope: an organization's
+// own stacks and those of all its projects, or a project's stacks.
+// skipped counts projects that could not be listed.
+func listScopeStacks(client *api.Client) (refs []stackRef, skipped int, err error) {
+	refs, _, err = loadStackRefs(client, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	if scopeType, _ := client.Scope(); scopeType != "organization" {
+		return refs, 0, nil
+	}
+	projects, skipped, err := loadStackRefs(client, true)
+	if err != nil {
+		return nil, 0, err
+	}
+	return append(refs, projects...), skipped, nil
+}
+
+type blueprintLoadedMsg struct {
+	stacks  []stackRef
+	skipped int
+}
+
+// blueprintModel lists every stack instantiated from one blueprint across
+// the open scope, with the outcome of each stack's latest operation.
+type blueprintModel struct {
+	client      *api.Client
+	blueprintID string
+	styles      styles
+	spinner     spinner.Model
+	table       table.Model
+	loading     bool
+	err         error
+	width       int
+	height      int
+
+	data blueprintLoadedMsg
+	now  time.Time
+}
+
+func newBlueprintModel(client *api.Client, blueprintID string, s styles, width, height int) blueprintModel {
+	sp := spinner.New()
+	sp.Spinner = spinner.Dot
+
+	t := table.New(table.WithFocused(true), table.WithKeyMap(tableKeys()))
+	t.SetStyles(s.table)
+
+	m := blueprintModel{
+		client:      client,
+		blueprintID: blueprintID,
+		styles:      s,
+		spinner:     sp,
+		table:       t,
+		loading:     true,
+		now:         time.Now(),
+	}
+	m.SetSize(width, height)
+	return m
+}
+
+func (m blueprintModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.fetch())
+}
+
+func (m blueprintModel) Update(msg tea.Msg) (blueprintModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		if key.Matches(msg, appKeys.Refresh) {
+			return m.Refresh()
+		}
+
+	case blueprintLoadedMsg:
+		m.loading = false
+		m.err = nil
+		m.data = msg
+		m.now = time.Now()
+		m.setRows()
+		return m, nil
+
+	case apiErrMsg:
+		m.loading = false
+		m.err = msg.err
+
+	case spinner.TickMsg:
+		if m.loading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+	}
+
+	if !m.loading {
+		var cmd tea.Cmd
+		m.table, cmd = m.table.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m blueprintModel) Refresh() (blueprintModel, tea.Cmd) {
+	m.loading = true
+	m.err = nil
+	return m, tea.Batch(m.spinner.Tick, m.fetch())
+}
+
+// showProjects reports whether any stack lives in a project, which is
+// only the case in an organization scope.
+func (m blueprintModel) showProjects() bool {
+	for _, ref := range m.data.stacks {
+		if ref.project != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *blueprintModel) setRows() {
+	// Columns and rows must agree before either is set.
+	m.table.SetRows(nil)
+	m.table.SetColumns(blueprintColumns(m.width, m.showProjects()))
+	rows := make([]table.Row, len(m.data.stacks))
+	for i, ref := range m.data.stacks {
+		st := ref.stack
+		indicator := m.styles.statusIndicator("")
+		if op := st.RecentOperation; op != nil {
+			indicator = m.styles.statusIndicator(op.Status)
+		}
+		resources := ""
+		if n := st.DisplayResourceCount(); n != nil {
+			resources = fmt.Sprint(*n)
+		}
+		rows[i] = table.Row{indicator, ref.project, st.Name, st.ID, resources, lastOperation(st, m.now)}
+		if !m.showProjects() {
+			rows[i] = append(rows[i][:1], rows[i][2:]...)
+		}
+	}
+	m.table.SetRows(rows)
+	m.table.SetCursor(0)
+}
+
+// blueprintColumns gives the project and stack names what is left after
+// the fixed-width columns. The project column is left out when projects
+// is false.
+func blueprintColumns(width int, projects bool) []table.Column {
+	const fixed = 3 + 16 + 10 + 22
+	flex := max(width-fixed-2*6, 20)
+	cols := []table.Column{
+		{Title: " ", Width: 3},
+		{Title: "Project", Width: flex * 2 / 5},
+		{Title: "Name", Width: flex - flex*2/5},
+		{Title: "ID", Width: 16},
+		{Title: "Resources", Width: 10},
+		{Title: "Last operation", Width: 22},
+	}
+	if !projects {
+		cols[2].Width = flex
+		cols = append(cols[:1], cols[2:]...)
+	}
+	return cols
+}
+
+func (m blueprintModel) selected() (stackRef, bool) {
+	idx := m.table.Cursor()
+	if idx < 0 || idx >= len(m.data.stacks) {
+		return stackRef{}, false
+	}
+	return m.data.stacks[idx], true
+}
+
+// View returns exactly m.height lines: a summary line and the table.
+func (m blueprintModel) View() string {
+	s := m.styles
+	if m.err != nil {
+		c := s.title.Render("Error") + "\n\n" + m.err.Error() + "\n\n" + s.muted.Render("Press "+appKeys.Refresh.Help().Key+" to retry")
+		return lipgloss.PlaceVertical(m.height, lipgloss.Top, c)
+	}
+	if m.loading {
+		return lipgloss.PlaceVertical(m.height, lipgloss.Top, m.spinner.View()+" Loading stacks…")
+	}
+	if len(m.data.stacks) == 0 {
+		c := m.renderSummary() + "\n\n" + s.muted.Render("No stacks use this blueprint.")
+		return lipgloss.PlaceVertical(m.height, lipgloss.Top, c)
+	}
+	return lipgloss.PlaceVertical(m.height, lipgloss.Top, m.renderSummary()+"\n"+m.table.View())
+}
+
+func (m blueprintModel) renderSummary() string {
+	s := m.styles
+	status, summary := groupStatus(m.data.stacks)
+	line := s.statusIndicator(status) + " " + s.headerValue.Render(countNoun(len(m.data.stacks), "stack", "stacks"))
+	if summary != "" {
+		line += s.muted.Render("  ·  " + summary)
+	}
+	if m.data.skipped > 0 {
+		line += "   " + s.logWarn.Render(countNoun(m.data.skipped, "project", "projects")+" could not be read")
+	}
+	return line
+}
+
+func (m *blueprintModel) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.table.SetColumns(blueprintColumns(w, m.showProjects()))
+	m.table.SetWidth(w)
+	m.table.SetHeight(max(h-1, 1))
+}
+
+func (m blueprintModel) fetch() tea.Cmd {
+	client, blueprintID := m.client, m.blueprintID
+	return func() tea.Msg {
+		refs, skipped, err := listScopeStacks(client)
+		if err != nil {
+			return apiErrMsg{err: err}
+		}
+		msg := blueprintLoadedMsg{skipped: skipped}
+		for _, ref := range refs {
+			if ref.stack.BlueprintID == blueprintID {
+				msg.stacks = append(msg.stacks, ref)
+			}
+		}
+		sort.SliceStable(msg.stacks, func(i, j int) bool {
+			a, b := msg.stacks[i], msg.stacks[j]
+			if a.project != b.project {
+				return a.project < b.project
+			}
+			return a.stack.Name < b.stack.Name
+		})
+		return msg
+	}
+}
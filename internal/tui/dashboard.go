@@ -0,0 +1,309 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/spinner"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+)
+
+const (
+	// dashboardFailures is how many recent failed operations are shown.
+	dashboardFailures = 5
+	// dashboardActivity is how many recent operations the feed shows.
+	dashboardActivity = 12
+	// dashboardOpsPerStack bounds the operations fetched per stack for
+	// the activity feed.
+	dashboardOpsPerStack = 5
+)
+
+type dashboardLoadedMsg struct {
+	stacks     []stackRef
+	skipped    int
+	activity   []opRef
+	failures   []opRef
+	firstError map[string]string
+}
+
+// dashboardTickMsg advances the elapsed times of running operations on
+// the dashboard numbered id.
+type dashboardTickMsg struct {
+	id   int
+	time time.Time
+}
+
+// dashboardModel summarizes the open scope: stacks by the status of their
+// latest operation, running operations, recent failures and a feed of
+// recent activity. Every operation line opens its operation.
+type dashboardModel struct {
+	client      *api.Client
+	allProjects bool
+	styles      styles
+	spinner     spinner.Model
+	loading     bool
+	err         error
+	width       int
+	height      int
+
+	data   dashboardLoadedMsg
+	items  []opRef
+	cursor int
+	now    time.Time
+
+	// id tells this dashboard's ticks from others'; ticking is set while
+	// its one tick chain runs.
+	id      int
+	ticking bool
+}
+
+func newDashboardModel(client *api.Client, id int, allProjects bool, s styles, width, height int) dashboardModel {
+	sp := spinner.New()
+	sp.Spinner = spinner.Dot
+	return dashboardModel{
+		client:      client,
+		allProjects: allProjects,
+		styles:      s,
+		spinner:     sp,
+		loading:     true,
+		width:       width,
+		height:      height,
+		now:         time.Now(),
+		id:          id,
+	}
+}
+
+func (m dashboardModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.fetch())
+}
+
+func (m dashboardModel) Update(msg tea.Msg) (dashboardModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, appKeys.Refresh):
+			return m.Refresh()
+		case key.Matches(msg, appKeys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case key.Matches(msg, appKeys.Down):
+			if m.cursor < len(m.items)-1 {
+				m.cursor++
+			}
+		case key.Matches(msg, appKeys.Top):
+			m.cursor = 0
+		case key.Matches(msg, appKeys.Bottom):
+			m.cursor = max(len(m.items)-1, 0)
+		}
+		return m, nil
+
+	case dashboardLoadedMsg:
+		m.loading = false
+		m.err = nil
+		m.data = msg
+		m.now = time.Now()
+		m.items = append(append(m.running(), msg.failures...), msg.activity...)
+		m.cursor = min(m.cursor, max(len(m.items)-1, 0))
+		if len(m.running()) > 0 && !m.ticking {
+			m.ticking = true
+			return m, m.tick()
+		}
+		return m, nil
+
+	case dashboardTickMsg:
+		if msg.id != m.id {
+			return m, nil
+		}
+		m.now = msg.time
+		if len(m.running()) > 0 {
+			return m, m.tick()
+		}
+		m.ticking = false
+		return m, nil
+
+	case apiErrMsg:
+		m.loading = false
+		m.err = msg.err
+
+	case spinner.TickMsg:
+		if m.loading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+func (m dashboardModel) tick() tea.Cmd {
+	id := m.id
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return dashboardTickMsg{id: id, time: t} })
+}
+
+func (m dashboardModel) Refresh() (dashboardModel, tea.Cmd) {
+	m.loading = true
+	m.err = nil
+	return m, tea.Batch(m.spinner.Tick, m.fetch())
+}
+
+// running returns the operations still in progress: those in the
+// activity feed plus any stack whose latest operation is running but fell
+// outside the feed.
+func (m dashboardModel) running() []opRef {
+	var out []opRef
+	seen := make(map[string]bool)
+	for _, ref := range m.data.activity {
+		if isInProgress(ref.op.Status) {
+			out = append(out, ref)
+			seen[ref.op.ID] = true
+		}
+	}
+	for _, s := range m.data.stacks {
+		if op := s.stack.RecentOperation; op != nil && isInProgress(op.Status) && !seen[op.ID] {
+			out = append(out, opRef{op: *op, stack: s})
+		}
+	}
+	sortOpsNewestFirst(out)
+	return out
+}
+
+func (m dashboardModel) selected() (opRef, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.items) {
+		return opRef{}, false
+	}
+	return m.items[m.cursor], true
+}
+
+// View returns exactly m.height lines, scrolled to keep the cursor in view.
+func (m dashboardModel) View() string {
+	s := m.styles
+	if m.err != nil {
+		c := s.title.Render("Error") + "\n\n" + m.err.Error() + "\n\n" + s.muted.Render("Press "+appKeys.Refresh.Help().Key+" to retry")
+		return lipgloss.PlaceVertical(m.height, lipgloss.Top, c)
+	}
+	if m.loading {
+		return lipgloss.PlaceVertical(m.height, lipgloss.Top, m.spinner.View()+" Loading dashboard…")
+	}
+
+	var lines []string
+	cursorLine := 0
+	idx := 0
+	section := func(title string, ops []opRef, empty string, render func(opRef) string) {
+		lines = append(lines, "")
+		lines = append(lines, strings.Split(s.sectionHead.Render(fmt.Sprintf("%s (%d)", title, len(ops))), "\n")...)
+		if len(ops) == 0 {
+			lines = append(lines, s.muted.Render("  "+empty))
+		}
+		for _, ref := range ops {
+			prefix := "  "
+			line := render(ref)
+			if idx == m.cursor {
+				prefix = s.title.Render("▸ ")
+				cursorLine = len(lines)
+			}
+			lines = append(lines, lipgloss.NewStyle().MaxWidth(m.width).Render(prefix+line))
+			idx++
+		}
+	}
+
+	lines = append(lines, m.renderCounts())
+	section("Running", m.running(), "Nothing running.", func(r opRef) string {
+		return s.statusIndicator(r.op.Status) + " " + m.stackName(r) + "  " + s.muted.Render(r.op.ID) +
+			"  " + s.statusInProgress.Render(formatDuration(m.now.Sub(r.op.CreatedAt)))
+	})
+	section("Recent failures", m.data.failures, "No failed operations.", func(r opRef) string {
+		line := s.statusIndicator(r.op.Status) + " " + m.stackName(r) + "  " + s.muted.Render(r.op.ID+"  "+formatAge(r.op.CreatedAt, m.now))
+		if msg, ok := m.data.firstError[r.op.ID]; ok {
+			line += "  " + s.logError.Render(msg)
+		}
+		return line
+	})
+	section("Activity", m.data.activity, "No recent operations.", func(r opRef) string {
+		return s.statusIndicator(r.op.Status) + " " + m.stackName(r) + "  " +
+			s.muted.Render(r.op.ID+"  "+strings.ToLower(r.op.Status)+"  "+formatAge(r.op.CreatedAt, m.now))
+	})
+
+	start := 0
+	if cursorLine >= m.height {
+		start = cursorLine - m.height + 1
+	}
+	end := min(start+m.height, len(lines))
+	return lipgloss.PlaceVertical(m.height, lipgloss.Top, strings.Join(lines[start:end], "\n"))
+}
+
+func (m dashboardModel) stackName(r opRef) string {
+	name := m.styles.headerValue.Render(r.stack.stack.Name)
+	if r.stack.project != "" {
+		name = m.styles.muted.Render(r.stack.project+" / ") + name
+	}
+	return name
+}
+
+// renderCounts summarizes stacks by the status of their latest operation.
+func (m dashboardModel) renderCounts() string {
+	s := m.styles
+	var completed, failed, running, none int
+	for _, ref := range m.data.stacks {
+		op := ref.stack.RecentOperation
+		switch {
+		case op == nil:
+			none++
+		case isInProgress(op.Status):
+			running++
+		case strings.EqualFold(op.Status, "FAILED"):
+			failed++
+		case strings.EqualFold(op.Status, "COMPLETED"), strings.EqualFold(op.Status, "SUCCESS"):
+			completed++
+		default:
+			none++
+		}
+	}
+	parts := []string{
+		s.headerValue.Render(fmt.Sprintf("%d stacks", len(m.data.stacks))),
+		s.statusIndicator("COMPLETED") + fmt.Sprintf(" %d completed", completed),
+		s.statusIndicator("FAILED") + fmt.Sprintf(" %d failed", failed),
+		s.statusIndicator("IN_PROGRESS") + fmt.Sprintf(" %d in progress", running),
+		s.statusIndicator("") + fmt.Sprintf(" %d no status", none),
+	}
+	line := strings.Join(parts, "   ")
+	if m.data.skipped > 0 {
+		line += "   " + s.logWarn.Render(countNoun(m.data.skipped, "project", "projects")+" could not be read")
+	}
+	return line
+}
+
+func (m *dashboardModel) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+}
+
+func (m dashboardModel) fetch() tea.Cmd {
+	client, allProjects := m.client, m.allProjects
+	return func() tea.Msg {
+		refs, skipped, err := loadStackRefs(client, allProjects)
+		if err != nil {
+			return apiErrMsg{err: err}
+		}
+		activity, _ := listOperationsAcross(refs, api.ListOperationsOpts{Limit: dashboardOpsPerStack})
+		if len(activity) > dashboardActivity {
+			activity = activity[:dashboardActivity]
+		}
+		failures, _ := listOperationsAcross(refs, api.ListOperationsOpts{Status: "FAILED", Limit: dashboardFailures})
+		failures = onlyFailed(failures)
+		if len(failures) > dashboardFailures {
+			failures = failures[:dashboardFailures]
+		}
+		return dashboardLoadedMsg{
+			stacks:     refs,
+			skipped:    skipped,
+			activity:   activity,
+			failures:   failures,
+			firstError: firstErrorLogs(failures),
+		}
+	}
+}
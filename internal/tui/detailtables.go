@@ -0,0 +1,374 @@
+package tui
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/table"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+)
+
+// tableQuery is the filter and sort applied to one of the stack detail
+// tables.
+type tableQuery struct {
+	input textinput.Model
+	// editing is set while the filter input has focus.
+	editing bool
+	// sort indexes the table's sortable columns, or is -1 for API order.
+	sort int
+	desc bool
+}
+
+func newTableQuery() tableQuery {
+	ti := textinput.New()
+	ti.Prompt = "/ "
+	ti.Placeholder = "filter"
+	return tableQuery{input: ti, sort: -1}
+}
+
+func (q tableQuery) text() string {
+	return strings.ToLower(strings.TrimSpace(q.input.Value()))
+}
+
+// matches reports whether any of the cells contains the filter text.
+func (q tableQuery) matches(cells ...string) bool {
+	text := q.text()
+	if text == "" {
+		return true
+	}
+	for _, c := range cells {
+		if strings.Contains(strings.ToLower(c), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortable is a column rows can be sorted by: its index among the table
+// columns and an ascending order.
+type sortable[T any] struct {
+	col  int
+	less func(a, b T) bool
+}
+
+var resourceSorts = []sortable[api.Resource]{
+	{col: 0, less: func(a, b api.Resource) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }},
+	{col: 1, less: func(a, b api.Resource) bool { return a.Type < b.Type }},
+	{col: 3, less: func(a, b api.Resource) bool { return a.CreatedAt.Before(b.CreatedAt) }},
+}
+
+var operationSorts = []sortable[api.Operation]{
+	{col: 2, less: func(a, b api.Operation) bool { return opStatusRank(a.Status) < opStatusRank(b.Status) }},
+	{col: 3, less: func(a, b api.Operation) bool { return a.CreatedAt.Before(b.CreatedAt) }},
+}
+
+// opStatusRank orders operation statuses failures first.
+func opStatusRank(status string) int {
+	switch {
+	case strings.EqualFold(status, "FAILED"):
+		return 0
+	case isInProgress(status):
+		return 1
+	case strings.EqualFold(status, "COMPLETED"), strings.EqualFold(status, "SUCCESS"):
+		return 2
+	}
+	return 3
+}
+
+// sortRows sorts rows in place by the query's column, keeping API order
+// for ties.
+func sortRows[T any](rows []T, sorts []sortable[T], q tableQuery) {
+	if q.sort < 0 {
+		return
+	}
+	less := sorts[q.sort].less
+	sort.SliceStable(rows, func(i, j int) bool {
+		if q.desc {
+			return less(rows[j], rows[i])
+		}
+		return less(rows[i], rows[j])
+	})
+}
+
+// markSorted appends the sort direction to the sorted column's title.
+func markSorted[T any](cols []table.Column, sorts []sortable[T], q tableQuery) []table.Column {
+	if q.sort < 0 {
+		return cols
+	}
+	c := &cols[sorts[q.sort].col]
+	if q.desc {
+		c.Title += " ▼"
+	} else {
+		c.Title += " ▲"
+	}
+	return cols
+}
+
+// nextSort moves q to the next of n sortable columns, ascending, and
+// after the last one back to API order.
+func (q tableQuery) nextSort(n int) tableQuery {
+	q.sort++
+	if q.sort >= n {
+		q.sort = -1
+	}
+	q.desc = false
+	return q
+}
+
+func (q tableQuery) reverseSort() tableQuery {
+	if q.sort < 0 {
+		q.sort = 0
+	}
+	q.desc = !q.desc
+	return q
+}
+
+// opStatusChips are the operation statuses the Operations tab can be
+// narrowed to. The status is passed to the API as ListOperationsOpts.Status.
+var opStatusChips = []struct {
+	label  string
+	status string
+}{
+	{"all", ""},
+	{"completed", "COMPLETED"},
+	{"failed", "FAILED"},
+	{"in progress", "IN_PROGRESS"},
+}
+
+// activeQuery returns the query of the active tab, if it has a table.
+func (m *stackDetailModel) activeQuery() (*tableQuery, bool) {
+	switch m.activeTab {
+	case tabResources:
+		return &m.resourceQuery, true
+	case tabOperations:
+		return &m.operationQuery, true
+	}
+	return nil, false
+}
+
+// filtering reports whether the filter input of the active tab has focus.
+func (m stackDetailModel) filtering() bool {
+	q, ok := m.activeQuery()
+	return ok && q.editing
+}
+
+// hasFilter reports whether the active tab's table is filtered.
+func (m stackDetailModel) hasFilter() bool {
+	q, ok := m.activeQuery()
+	return ok && q.text() != ""
+}
+
+// startFilter focuses the filter input of the active tab.
+func (m *stackDetailModel) startFilter() tea.Cmd {
+	q, ok := m.activeQuery()
+	if !ok {
+		return nil
+	}
+	q.editing = true
+	return q.input.Focus()
+}
+
+// clearFilter empties the active tab's filter.
+func (m *stackDetailModel) clearFilter() {
+	if q, ok := m.activeQuery(); ok {
+		q.input.SetValue("")
+		q.input.Blur()
+		q.editing = false
+		m.setTableRows()
+	}
+}
+
+// updateFilter feeds a key to the filter input: enter keeps the filter,
+// esc drops it and anything else edits it.
+func (m stackDetailModel) updateFilter(msg tea.KeyPressMsg) (stackDetailModel, tea.Cmd) {
+	q, _ := m.activeQuery()
+	switch {
+	case key.Matches(msg, appKeys.Select):
+		q.editing = false
+		q.input.Blur()
+		return m, nil
+	case key.Matches(msg, appKeys.Back):
+		m.clearFilter()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	q.input, cmd = q.input.Update(msg)
+	m.setTableRows()
+	return m, cmd
+}
+
+// sortBy cycles or reverses the sort of the active tab's table.
+func (m *stackDetailModel) sortBy(reverse bool) {
+	switch m.activeTab {
+	case tabResources:
+		if reverse {
+			m.resourceQuery = m.resourceQuery.reverseSort()
+		} else {
+			m.resourceQuery = m.resourceQuery.nextSort(len(resourceSorts))
+		}
+	case tabOperations:
+		if reverse {
+			m.operationQuery = m.operationQuery.reverseSort()
+		} else {
+			m.operationQuery = m.operationQuery.nextSort(len(operationSorts))
+		}
+	}
+	m.setTableRows()
+}
+
+// cycleStatusChip narrows the Operations tab to the next status and
+// reloads the operations with it.
+func (m *stackDetailModel) cycleStatusChip() tea.Cmd {
+	m.statusChip = (m.statusChip + 1) % len(opStatusChips)
+	m.loadingOperations = true
+	return tea.Batch(m.spinner.Tick, m.fetchOperations())
+}
+
+func (m *stackDetailModel) setTableRows() {
+	switch m.activeTab {
+	case tabResources:
+		m.setResourceRows()
+	case tabOperations:
+		m.setOperationRows()
+	}
+}
+
+// setResourceRows fills the resources table with the resources matching
+// the filter, in the chosen order, keeping the selected resource selected.
+// Grouped by type, each type is headed by a row with its count and the
+// chosen order applies within the type.
+func (m *stackDetailModel) setResourceRows() {
+	selected, _ := m.selectedResource()
+	m.shownResources = nil
+	for _, r := range m.resources {
+		if m.resourceQuery.matches(r.Name, r.Type, r.ID, r.CreatedAt.Format("2006-01-02 15:04")) {
+			m.shownResources = append(m.shownResources, r)
+		}
+	}
+	sortRows(m.shownResources, resourceSorts, m.resourceQuery)
+	if m.groupByType {
+		sort.SliceStable(m.shownResources, func(i, j int) bool {
+			return m.shownResources[i].Type < m.shownResources[j].Type
+		})
+	}
+
+	var rows []table.Row
+	m.resourceRows = nil
+	cursor := -1
+	for i, r := range m.shownResources {
+		name := r.Name
+		if m.groupByType {
+			if i == 0 || m.shownResources[i-1].Type != r.Type {
+				n := 1
+				for _, next := range m.shownResources[i+1:] {
+					if next.Type != r.Type {
+						break
+					}
+					n++
+				}
+				rows = append(rows, table.Row{"▾ " + r.Type, countNoun(n, "resource", "resources"), "", ""})
+				m.resourceRows = append(m.resourceRows, -1)
+			}
+			name = "  " + name
+		}
+		if r.ID == selected.ID || cursor < 0 {
+			cursor = len(rows)
+		}
+		rows = append(rows, table.Row{name, r.Type, r.ID, r.CreatedAt.Format("2006-01-02 15:04")})
+		m.resourceRows = append(m.resourceRows, i)
+	}
+	m.resourceTable.SetColumns(markSorted(resourceColumns(m.width), resourceSorts, m.resourceQuery))
+	m.resourceTable.SetRows(rows)
+	m.resourceTable.SetCursor(max(cursor, 0))
+}
+
+// resourceTypes counts the distinct types among the shown resources.
+func (m stackDetailModel) resourceTypes() int {
+	types := make(map[string]bool)
+	for _, r := range m.shownResources {
+		types[r.Type] = true
+	}
+	return len(types)
+}
+
+// setOperationRows fills the operations table with the operations
+// matching the status chip and the filter, in the chosen order, flagging
+// marked rows. The chip is applied again here in case the API ignores it.
+func (m *stackDetailModel) setOperationRows() {
+	selected, _ := m.selectedOperation()
+	status := opStatusChips[m.statusChip].status
+	m.shownOperations = nil
+	for _, op := range m.operations {
+		if status != "" && opStatusRank(op.Status) != opStatusRank(status) {
+			continue
+		}
+		if m.operationQuery.matches(op.ID, op.Status, op.CreatedAt.Format("2006-01-02 15:04")) {
+			m.shownOperations = append(m.shownOperations, op)
+		}
+	}
+	sortRows(m.shownOperations, operationSorts, m.operationQuery)
+
+	rows := make([]table.Row, len(m.shownOperations))
+	cursor := 0
+	for i, op := range m.shownOperations {
+		indicator := m.styles.statusIndicator(op.Status)
+		if slices.Contains(m.marked, op.ID) {
+			indicator += m.styles.title.Render("✓")
+		}
+		rows[i] = table.Row{
+			indicator,
+			op.ID,
+			op.Status,
+			op.CreatedAt.Format("2006-01-02 15:04"),
+		}
+		if op.ID == selected.ID {
+			cursor = i
+		}
+	}
+	m.operationTable.SetColumns(markSorted(operationColumns(m.width), operationSorts, m.operationQuery))
+	m.operationTable.SetRows(rows)
+	m.operationTable.SetCursor(cursor)
+}
+
+// renderToolbar renders the line above the Resources and Operations
+// tables: the status chips, then the filter input while it is edited, or
+// the filter and how many rows match.
+func (m stackDetailModel) renderToolbar() string {
+	s := m.styles
+	var line string
+	if m.activeTab == tabOperations {
+		for i, chip := range opStatusChips {
+			if i == m.statusChip {
+				line += s.tabActive.Render(chip.label)
+			} else {
+				line += s.tabInactive.Render(chip.label)
+			}
+		}
+		line += "  "
+	}
+
+	q, _ := m.activeQuery()
+	if q.editing {
+		return line + q.input.View()
+	}
+	shown, total, noun, nouns := len(m.shownResources), len(m.resources), "resource", "resources"
+	if m.activeTab == tabOperations {
+		shown, total, noun, nouns = len(m.shownOperations), len(m.operations), "operation", "operations"
+	}
+	count := countNoun(total, noun, nouns)
+	if shown != total {
+		count = strconv.Itoa(shown) + " of " + count
+	}
+	if m.activeTab == tabResources && m.groupByType {
+		count += " in " + countNoun(m.resourceTypes(), "type", "types")
+	}
+	if text := q.input.Value(); strings.TrimSpace(text) != "" {
+		return line + s.muted.Render("/ ") + text + s.muted.Render("  ·  "+count)
+	}
+	return line + s.muted.Render(count)
+}
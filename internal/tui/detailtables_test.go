@@ -0,0 +1,26 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNextSortReturnsToAPIOrder(t *testing.T) {
+	q := tableQuery{sort: -1}
+	var got []int
+	for range 4 {
+		q = q.nextSort(2)
+		got = append(got, q.sort)
+	}
+	if want := []int{0, 1, -1, 0}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("sort columns = %v, want %v", got, want)
+	}
+
+	q = q.reverseSort()
+	if !q.desc {
+		t.Fatal("reverseSort did not reverse")
+	}
+	if q = q.nextSort(2); q.desc {
+		t.Error("nextSort kept the descending order")
+	}
+}
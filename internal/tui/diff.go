@@ -0,0 +1,129 @@
+package tui
+
+// diffKind says which side of a diff a line belongs to.
+type diffKind int
+
+const (
+	diffSame diffKind = iota
+	diffRemoved
+	diffAdded
+)
+
+// diffLine is one line of a line diff. a and b index the line in the
+// old and new input; the side a line is missing from is -1.
+type diffLine struct {
+	kind diffKind
+	a, b int
+}
+
+// diffLines returns a shortest edit script turning a into b. It uses
+// Myers' linear-space algorithm, which takes O((n+m)·d) time for d
+// differing lines and O(n+m) memory, so long log streams that mostly
+// agree diff quickly and none need a quadratic table.
+func diffLines(a, b []string) []diffLine {
+	size := 2*((len(a)+len(b)+1)/2) + 3
+	d := differ{a: a, b: b, vf: make([]int, size), vb: make([]int, size)}
+	d.diff(0, len(a), 0, len(b))
+	return d.out
+}
+
+// differ holds the inputs, the script built so far and the furthest
+// reaching paths, shared by every level of the recursion.
+type differ struct {
+	a, b   []string
+	out    []diffLine
+	vf, vb []int
+}
+
+// diff appends the script for a[a0:a1] against b[b0:b1].
+func (d *differ) diff(a0, a1, b0, b1 int) {
+	for a0 < a1 && b0 < b1 && d.a[a0] == d.b[b0] {
+		d.out = append(d.out, diffLine{kind: diffSame, a: a0, b: b0})
+		a0++
+		b0++
+	}
+	suffix := 0
+	for a1-suffix > a0 && b1-suffix > b0 && d.a[a1-1-suffix] == d.b[b1-1-suffix] {
+		suffix++
+	}
+	a1, b1 = a1-suffix, b1-suffix
+
+	switch {
+	case a0 == a1:
+		for j := b0; j < b1; j++ {
+			d.out = append(d.out, diffLine{kind: diffAdded, a: -1, b: j})
+		}
+	case b0 == b1:
+		for i := a0; i < a1; i++ {
+			d.out = append(d.out, diffLine{kind: diffRemoved, a: i, b: -1})
+		}
+	default:
+		x, y, u, v := d.middleSnake(a0, a1, b0, b1)
+		d.diff(a0, x, b0, y)
+		for ; x < u; x, y = x+1, y+1 {
+			d.out = append(d.out, diffLine{kind: diffSame, a: x, b: y})
+		}
+		d.diff(u, a1, v, b1)
+	}
+
+	for i := range suffix {
+		d.out = append(d.out, diffLine{kind: diffSame, a: a1 + i, b: b1 + i})
+	}
+}
+
+// middleSnake finds the run of matching lines, from (x, y) to (u, v), in
+// the middle of a shortest script for a[a0:a1] against b[b0:b1], by
+// searching from both ends until the paths meet. Splitting there leaves
+// two problems with about half the differences each.
+func (d *differ) middleSnake(a0, a1, b0, b1 int) (x, y, u, v int) {
+	n, m := a1-a0, b1-b0
+	delta := n - m
+	odd := delta%2 != 0
+	limit := (n + m + 1) / 2
+	off := limit + 1
+	vf, vb := d.vf, d.vb
+	vf[off+1], vb[off+1] = 0, 0
+
+	for e := 0; e <= limit; e++ {
+		// Forward: vf[off+k] is how far along a the path on diagonal
+		// k = x-y reaches with e differences.
+		for k := -e; k <= e; k += 2 {
+			var xs int
+			if k == -e || (k != e && vf[off+k-1] < vf[off+k+1]) {
+				xs = vf[off+k+1]
+			} else {
+				xs = vf[off+k-1] + 1
+			}
+			xe, ye := xs, xs-k
+			for xe < n && ye < m && d.a[a0+xe] == d.b[b0+ye] {
+				xe++
+				ye++
+			}
+			vf[off+k] = xe
+			if kb := delta - k; odd && kb >= -(e-1) && kb <= e-1 && xe+vb[off+kb] >= n {
+				return a0 + xs, b0 + xs - k, a0 + xe, b0 + ye
+			}
+		}
+		// Backward: the same on the reversed inputs, so vb[off+k]
+		// counts lines from the ends of a and b.
+		for k := -e; k <= e; k += 2 {
+			var xs int
+			if k == -e || (k != e && vb[off+k-1] < vb[off+k+1]) {
+				xs = vb[off+k+1]
+			} else {
+				xs = vb[off+k-1] + 1
+			}
+			xe, ye := xs, xs-k
+			for xe < n && ye < m && d.a[a1-1-xe] == d.b[b1-1-ye] {
+				xe++
+				ye++
+			}
+			vb[off+k] = xe
+			if kf := delta - k; !odd && kf >= -e && kf <= e && xe+vf[off+kf] >= n {
+				return a1 - xe, b1 - ye, a1 - xs, b1 - xs + k
+			}
+		}
+	}
+	// Unreachable: the paths meet within limit differences.
+	return a0, b0, a0, b0
+}
@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// lcsLength is the quadratic longest common subsequence, for checking
+// that diffLines finds a shortest script.
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// checkScript reports whether script walks both inputs in order, keeps
+// only equal lines and keeps as many as the longest common subsequence.
+func checkScript(t *testing.T, a, b []string, script []diffLine) {
+	t.Helper()
+	i, j, same := 0, 0, 0
+	for _, d := range script {
+		switch d.kind {
+		case diffSame:
+			if d.a != i || d.b != j || a[i] != b[j] {
+				t.Fatalf("a=%v b=%v: bad same line %+v at %d,%d", a, b, d, i, j)
+			}
+			i, j, same = i+1, j+1, same+1
+		case diffRemoved:
+			if d.a != i || d.b != -1 {
+				t.Fatalf("a=%v b=%v: bad removed line %+v at %d", a, b, d, i)
+			}
+			i++
+		case diffAdded:
+			if d.b != j || d.a != -1 {
+				t.Fatalf("a=%v b=%v: bad added line %+v at %d", a, b, d, j)
+			}
+			j++
+		}
+	}
+	if i != len(a) || j != len(b) {
+		t.Fatalf("a=%v b=%v: script stops at %d,%d", a, b, i, j)
+	}
+	if want := lcsLength(a, b); same != want {
+		t.Fatalf("a=%v b=%v: script keeps %d lines, want %d", a, b, same, want)
+	}
+}
+
+func randomLines(r *rand.Rand, n, alphabet int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprint(r.Intn(alphabet))
+	}
+	return lines
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		a, b []string
+	}{
+		{nil, nil},
+		{[]string{"x"}, nil},
+		{nil, []string{"x"}},
+		{[]string{"a", "b", "c"}, []string{"a", "b", "c"}},
+		{[]string{"a", "b", "c"}, []string{"a", "c"}},
+		{[]string{"a", "c"}, []string{"a", "b", "c"}},
+		{[]string{"a", "b", "c", "a", "b", "b", "a"}, []string{"c", "b", "a", "b", "a", "c"}},
+	}
+	for _, tt := range tests {
+		checkScript(t, tt.a, tt.b, diffLines(tt.a, tt.b))
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for range 2000 {
+		a := randomLines(r, r.Intn(30), 1+r.Intn(5))
+		b := randomLines(r, r.Intn(30), 1+r.Intn(5))
+		checkScript(t, a, b, diffLines(a, b))
+	}
+}
+
+func TestDiffLinesLarge(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	a := randomLines(r, 5000, 1000)
+	b := randomLines(r, 5000, 1000)
+	start := time.Now()
+	script := diffLines(a, b)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("diffing 5000 lines took %s", elapsed)
+	}
+	if len(script) < 5000 {
+		t.Errorf("script has %d lines, want at least 5000", len(script))
+	}
+}
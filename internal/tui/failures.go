@@ -0,0 +1,237 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/spinner"
+	"charm.land/bubbles/v2/table"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+)
+
+const (
+	// failuresPerStack bounds the failed operations fetched per stack.
+	failuresPerStack = 10
+	// maxFailures caps the triage list, and so the log requests made for
+	// error previews.
+	maxFailures = 50
+	// failurePreviewLines is the height of the error preview under the
+	// table.
+	failurePreviewLines = 3
+)
+
+type failuresLoadedMsg struct {
+	failures   []opRef
+	skipped    int
+	unread     int
+	firstError map[string]string
+}
+
+// failuresModel lists failed operations across every stack in scope,
+// newest first, with the first error each one logged.
+type failuresModel struct {
+	client      *api.Client
+	allProjects bool
+	styles      styles
+	spinner     spinner.Model
+	table       table.Model
+	loading     bool
+	err         error
+	width       int
+	height      int
+
+	data failuresLoadedMsg
+	now  time.Time
+}
+
+func newFailuresModel(client *api.Client, allProjects bool, s styles, width, height int) failuresModel {
+	sp := spinner.New()
+	sp.Spinner = spinner.Dot
+
+	t := table.New(table.WithFocused(true), table.WithKeyMap(tableKeys()))
+	t.SetStyles(s.table)
+
+	m := failuresModel{
+		client:      client,
+		allProjects: allProjects,
+		styles:      s,
+		spinner:     sp,
+		table:       t,
+		loading:     true,
+		now:         time.Now(),
+	}
+	m.SetSize(width, height)
+	return m
+}
+
+func (m failuresModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.fetch())
+}
+
+func (m failuresModel) Update(msg tea.Msg) (failuresModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		if key.Matches(msg, appKeys.Refresh) {
+			return m.Refresh()
+		}
+
+	case failuresLoadedMsg:
+		m.loading = false
+		m.err = nil
+		m.data = msg
+		m.now = time.Now()
+		m.setRows()
+		return m, nil
+
+	case apiErrMsg:
+		m.loading = false
+		m.err = msg.err
+
+	case spinner.TickMsg:
+		if m.loading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+	}
+
+	if !m.loading {
+		var cmd tea.Cmd
+		m.table, cmd = m.table.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m failuresModel) Refresh() (failuresModel, tea.Cmd) {
+	m.loading = true
+	m.err = nil
+	return m, tea.Batch(m.spinner.Tick, m.fetch())
+}
+
+func (m *failuresModel) setRows() {
+	rows := make([]table.Row, len(m.data.failures))
+	for i, ref := range m.data.failures {
+		stack := ref.stack.stack.Name
+		if ref.stack.project != "" {
+			stack = ref.stack.project + " / " + stack
+		}
+		duration := ""
+		if ref.op.CompletedAt != nil {
+			duration = formatDuration(opDuration(ref.op, m.now))
+		}
+		rows[i] = table.Row{
+			m.styles.statusIndicator(ref.op.Status),
+			stack,
+			ref.op.ID,
+			formatAge(ref.op.CreatedAt, m.now),
+			duration,
+			strings.ReplaceAll(m.data.firstError[ref.op.ID], "\n", " "),
+		}
+	}
+	m.table.SetRows(rows)
+	m.table.SetCursor(0)
+}
+
+// failureColumns gives the stack name and the error preview what is left
+// after the fixed-width columns.
+func failureColumns(width int) []table.Column {
+	const fixed = 3 + 16 + 10 + 9
+	flex := max(width-fixed-2*6, 30)
+	return []table.Column{
+		{Title: " ", Width: 3},
+		{Title: "Stack", Width: flex * 2 / 5},
+		{Title: "Operation", Width: 16},
+		{Title: "Failed", Width: 10},
+		{Title: "Duration", Width: 9},
+		{Title: "First error", Width: flex - flex*2/5},
+	}
+}
+
+func (m failuresModel) selected() (opRef, bool) {
+	idx := m.table.Cursor()
+	if idx < 0 || idx >= len(m.data.failures) {
+		return opRef{}, false
+	}
+	return m.data.failures[idx], true
+}
+
+// View returns exactly m.height lines: a summary line, the table and the
+// full first error of the selected operation.
+func (m failuresModel) View() string {
+	s := m.styles
+	if m.err != nil {
+		c := s.title.Render("Error") + "\n\n" + m.err.Error() + "\n\n" + s.muted.Render("Press "+appKeys.Refresh.Help().Key+" to retry")
+		return lipgloss.PlaceVertical(m.height, lipgloss.Top, c)
+	}
+	if m.loading {
+		return lipgloss.PlaceVertical(m.height, lipgloss.Top, m.spinner.View()+" Loading failed operations…")
+	}
+	if len(m.data.failures) == 0 {
+		c := m.renderSummary() + "\n\n" + s.muted.Render("No failed operations.")
+		return lipgloss.PlaceVertical(m.height, lipgloss.Top, c)
+	}
+
+	var preview string
+	if ref, ok := m.selected(); ok {
+		msg, found := m.data.firstError[ref.op.ID]
+		if !found {
+			msg = s.muted.Render("No ERROR or FATAL log for this operation.")
+		} else {
+			msg = s.logError.Render(msg)
+		}
+		preview = lipgloss.NewStyle().Width(m.width).MaxHeight(failurePreviewLines - 1).Render(msg)
+	}
+	preview = lipgloss.PlaceVertical(failurePreviewLines, lipgloss.Bottom, preview)
+
+	return lipgloss.PlaceVertical(m.height, lipgloss.Top,
+		m.renderSummary()+"\n"+m.table.View()+"\n"+preview)
+}
+
+func (m failuresModel) renderSummary() string {
+	s := m.styles
+	line := s.headerValue.Render(countNoun(len(m.data.failures), "failed operation", "failed operations"))
+	var warn []string
+	if m.data.skipped > 0 {
+		warn = append(warn, countNoun(m.data.skipped, "project", "projects")+" could not be read")
+	}
+	if m.data.unread > 0 {
+		warn = append(warn, countNoun(m.data.unread, "stack", "stacks")+" could not be read")
+	}
+	if len(warn) > 0 {
+		line += "   " + s.logWarn.Render(strings.Join(warn, ", "))
+	}
+	return line
+}
+
+func (m *failuresModel) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.table.SetColumns(failureColumns(w))
+	m.table.SetWidth(w)
+	m.table.SetHeight(max(h-1-failurePreviewLines, 1))
+}
+
+func (m failuresModel) fetch() tea.Cmd {
+	client, allProjects := m.client, m.allProjects
+	return func() tea.Msg {
+		refs, skipped, err := loadStackRefs(client, allProjects)
+		if err != nil {
+			return apiErrMsg{err: err}
+		}
+		failures, unread := listOperationsAcross(refs, api.ListOperationsOpts{Status: "FAILED", Limit: failuresPerStack})
+		failures = onlyFailed(failures)
+		if len(failures) > maxFailures {
+			failures = failures[:maxFailures]
+		}
+		return failuresLoadedMsg{
+			failures:   failures,
+			skipped:    skipped,
+			unread:     unread,
+			firstError: firstErrorLogs(failures),
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package tui
+
+import "sync"
+
+// maxConcurrentRequests bounds fan-out across projects and stacks so a
+// large organization does not open hundreds of connections at once.
+const maxConcurrentRequests = 6
+
+// forEachLimit calls fn for every item with at most limit calls in flight
+// and returns once all have finished.
+func forEachLimit[T any](items []T, limit int, fn func(T)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, limit)
+	for _, it := range items {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(it T) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(it)
+		}(it)
+	}
+	wg.Wait()
+}
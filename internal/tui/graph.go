@@ -0,0 +1,210 @@
+package tui
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+)
+
+// resourceRef is a reference from one resource to another: a string in
+// the parameters of resources[from], at path, naming resources[to] by
+// name or ID.
+type resourceRef struct {
+	from, to int
+	path     string
+}
+
+// resourceGraph is the references between a stack's resources.
+type resourceGraph struct {
+	resources []api.Resource
+	// deps holds the references each resource makes; users the
+	// references made to it.
+	deps, users [][]resourceRef
+	refs        int
+}
+
+// newResourceGraph finds every parameter whose value is exactly the name
+// or ID of another resource in the stack. A resource refers to another at
+// most once, at the first path in sorted order.
+func newResourceGraph(resources []api.Resource) resourceGraph {
+	g := resourceGraph{
+		resources: resources,
+		deps:      make([][]resourceRef, len(resources)),
+		users:     make([][]resourceRef, len(resources)),
+	}
+	byKey := make(map[string][]int)
+	for i, r := range resources {
+		if r.ID != "" {
+			byKey[r.ID] = append(byKey[r.ID], i)
+		}
+		if r.Name != "" && r.Name != r.ID {
+			byKey[r.Name] = append(byKey[r.Name], i)
+		}
+	}
+
+	for i, r := range resources {
+		flat := make(map[string]string)
+		flattenValue("", r.Parameters, flat)
+		paths := make([]string, 0, len(flat))
+		for p := range flat {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+
+		seen := make(map[int]bool)
+		for _, p := range paths {
+			var s string
+			if json.Unmarshal([]byte(flat[p]), &s) != nil {
+				continue // not a string
+			}
+			for _, j := range byKey[s] {
+				if j == i || seen[j] {
+					continue
+				}
+				seen[j] = true
+				ref := resourceRef{from: i, to: j, path: p}
+				g.deps[i] = append(g.deps[i], ref)
+				g.users[j] = append(g.users[j], ref)
+				g.refs++
+			}
+		}
+	}
+	return g
+}
+
+// graphRow is one line of the drawn graph.
+type graphRow struct {
+	resource int
+	// prefix is the tree drawn before the resource.
+	prefix string
+	// via is the path of the reference to the parent row's resource.
+	via string
+	// repeat marks a resource drawn further up, whose users are not
+	// drawn again; cycle marks one that refers back to itself.
+	repeat, cycle bool
+}
+
+// rows lays the graph out as a tree. Resources that refer to nothing are
+// roots, those referred to first, and each resource is drawn under the
+// resources it refers to, so a resource reads as a dependency of what is
+// below it. A resource with several dependencies is drawn in full once.
+// Resources only reachable through a cycle are drawn from the first of
+// them.
+func (g resourceGraph) rows() []graphRow {
+	var rows []graphRow
+	drawn := make([]bool, len(g.resources))
+	onPath := make(map[int]bool)
+
+	var walk func(i int, lead, branch, cont, via string)
+	walk = func(i int, lead, branch, cont, via string) {
+		row := graphRow{resource: i, prefix: lead + branch, via: via}
+		switch {
+		case onPath[i]:
+			row.cycle = true
+		case drawn[i]:
+			row.repeat = true
+		}
+		rows = append(rows, row)
+		if row.cycle || drawn[i] {
+			return
+		}
+		drawn[i] = true
+		onPath[i] = true
+		for k, ref := range g.users[i] {
+			if k == len(g.users[i])-1 {
+				walk(ref.from, lead+cont, "└─ ", "   ", ref.path)
+			} else {
+				walk(ref.from, lead+cont, "├─ ", "│  ", ref.path)
+			}
+		}
+		delete(onPath, i)
+	}
+
+	var roots, isolated []int
+	for i := range g.resources {
+		switch {
+		case len(g.deps[i]) > 0:
+		case len(g.users[i]) > 0:
+			roots = append(roots, i)
+		default:
+			isolated = append(isolated, i)
+		}
+	}
+	for _, i := range append(roots, isolated...) {
+		walk(i, "", "", "", "")
+	}
+	for i := range g.resources {
+		if !drawn[i] {
+			walk(i, "", "", "", "")
+		}
+	}
+	return rows
+}
+
+// renderGraph draws the Graph tab: a summary line and the tree, scrolled
+// to keep the cursor in view.
+func (m stackDetailModel) renderGraph() string {
+	s := m.styles
+	g := m.graph
+	summary := countNoun(g.refs, "reference", "references") + " between " + countNoun(len(g.resources), "resource", "resources")
+	if g.refs > 0 {
+		summary += "  ·  each resource is drawn under those it refers to"
+	}
+
+	lines := make([]string, len(m.graphRows))
+	for i, row := range m.graphRows {
+		r := g.resources[row.resource]
+		line := s.muted.Render(row.prefix) + s.headerValue.Render(r.Name) + "  " + s.muted.Render(r.Type)
+		if row.via != "" {
+			line += s.muted.Render("  via " + row.via)
+		}
+		switch {
+		case row.cycle:
+			line += "  " + s.logWarn.Render("↻ cycle")
+		case row.repeat:
+			line += "  " + s.muted.Render("↑ see above")
+		}
+		prefix := "  "
+		if i == m.graphCursor {
+			prefix = s.title.Render("▸ ")
+		}
+		lines[i] = prefix + line
+	}
+
+	visible := max(m.innerHeight()-2, 1) // summary and blank line
+	start := 0
+	if m.graphCursor >= visible {
+		start = m.graphCursor - visible + 1
+	}
+	end := min(start+visible, len(lines))
+	return s.muted.Render(summary) + "\n\n" + strings.Join(lines[start:end], "\n")
+}
+
+// setGraph rebuilds the graph from the loaded resources, keeping the
+// cursor on the same resource where it can.
+func (m *stackDetailModel) setGraph() {
+	selected, _ := m.selectedGraphResource()
+	m.graph = newResourceGraph(m.resources)
+	m.graphRows = m.graph.rows()
+	m.graphCursor = 0
+	for i, row := range m.graphRows {
+		if m.resources[row.resource].ID == selected.ID {
+			m.graphCursor = i
+			break
+		}
+	}
+}
+
+// moveGraphCursor moves the graph cursor by delta rows, within the graph.
+func (m *stackDetailModel) moveGraphCursor(delta int) {
+	m.graphCursor = min(max(m.graphCursor+delta, 0), max(len(m.graphRows)-1, 0))
+}
+
+func (m stackDetailModel) selectedGraphResource() (api.Resource, bool) {
+	if m.graphCursor < 0 || m.graphCursor >= len(m.graphRows) {
+		return api.Resource{}, false
+	}
+	return m.graph.resources[m.graphRows[m.graphCursor].resource], true
+}
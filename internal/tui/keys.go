@@ -1,6 +1,17 @@
 package tui
 
-import "charm.land/bubbles/v2/key"
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/list"
+	"charm.land/bubbles/v2/table"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"github.com/sanity-labs/blueprints-tui/internal/config"
+)
 
 type appKeyMap struct {
 	Quit     key.Binding
@@ -10,6 +21,42 @@ type appKeyMap struct {
 	ShiftTab key.Binding
 	Refresh  key.Binding
 	Help     key.Binding
+
+	ReloadToken key.Binding
+	Favourite   key.Binding
+	Expand      key.Binding
+	SwitchScope key.Binding
+	AllProjects key.Binding
+	Dashboard   key.Binding
+	Failures    key.Binding
+	Timeline    key.Binding
+	Mark        key.Binding
+	Compare     key.Binding
+	CompareWith key.Binding
+	History     key.Binding
+
+	GroupByBlueprint key.Binding
+	Layout           key.Binding
+	Sort             key.Binding
+	ReverseSort      key.Binding
+	Filter           key.Binding
+	StatusFilter     key.Binding
+	GroupByType      key.Binding
+	Search           key.Binding
+	Palette          key.Binding
+
+	// Navigation in lists, tables, viewports and the views with a cursor
+	// of their own.
+	Up           key.Binding
+	Down         key.Binding
+	Left         key.Binding
+	Right        key.Binding
+	PageUp       key.Binding
+	PageDown     key.Binding
+	HalfPageUp   key.Binding
+	HalfPageDown key.Binding
+	Top          key.Binding
+	Bottom       key.Binding
 }
 
 var appKeys = appKeyMap{
@@ -41,15 +88,406 @@ var appKeys = appKeyMap{
 		key.WithKeys("?"),
 		key.WithHelp("?", "help"),
 	),
+	ReloadToken: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "re-read token"),
+	),
+	Favourite: key.NewBinding(
+		key.WithKeys("*"),
+		key.WithHelp("*", "favourite"),
+	),
+	Expand: key.NewBinding(
+		key.WithKeys("space"),
+		key.WithHelp("space", "expand/collapse"),
+	),
+	SwitchScope: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "switch scope"),
+	),
+	AllProjects: key.NewBinding(
+		key.WithKeys("A"),
+		key.WithHelp("A", "all projects"),
+	),
+	Dashboard: key.NewBinding(
+		key.WithKeys("D"),
+		key.WithHelp("D", "dashboard"),
+	),
+	Failures: key.NewBinding(
+		key.WithKeys("F"),
+		key.WithHelp("F", "failures"),
+	),
+	Timeline: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "timeline"),
+	),
+	Mark: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "mark"),
+	),
+	Compare: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "compare"),
+	),
+	CompareWith: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "compare stack"),
+	),
+	History: key.NewBinding(
+		key.WithKeys("H"),
+		key.WithHelp("H", "history"),
+	),
+	GroupByBlueprint: key.NewBinding(
+		key.WithKeys("B"),
+		key.WithHelp("B", "group by blueprint"),
+	),
+	Layout: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "list/table"),
+	),
+	Sort: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "sort column"),
+	),
+	ReverseSort: key.NewBinding(
+		key.WithKeys("O"),
+		key.WithHelp("O", "reverse sort"),
+	),
+	Filter: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "filter"),
+	),
+	StatusFilter: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "status filter"),
+	),
+	GroupByType: key.NewBinding(
+		key.WithKeys("T"),
+		key.WithHelp("T", "group by type"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("ctrl+f"),
+		key.WithHelp("ctrl+f", "search"),
+	),
+	Palette: key.NewBinding(
+		key.WithKeys(":", "ctrl+p"),
+		key.WithHelp(":", "commands"),
+	),
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑", "up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓", "down"),
+	),
+	Left: key.NewBinding(
+		key.WithKeys("left", "h"),
+		key.WithHelp("←", "left"),
+	),
+	Right: key.NewBinding(
+		key.WithKeys("right", "l"),
+		key.WithHelp("→", "right"),
+	),
+	PageUp: key.NewBinding(
+		key.WithKeys("pgup", "b"),
+		key.WithHelp("pgup", "page up"),
+	),
+	PageDown: key.NewBinding(
+		key.WithKeys("pgdown", "f"),
+		key.WithHelp("pgdown", "page down"),
+	),
+	HalfPageUp: key.NewBinding(
+		key.WithKeys("u", "ctrl+u"),
+		key.WithHelp("u", "½ page up"),
+	),
+	HalfPageDown: key.NewBinding(
+		key.WithKeys("d", "ctrl+d"),
+		key.WithHelp("d", "½ page down"),
+	),
+	Top: key.NewBinding(
+		key.WithKeys("home", "g"),
+		key.WithHelp("home", "go to top"),
+	),
+	Bottom: key.NewBinding(
+		key.WithKeys("end", "G"),
+		key.WithHelp("end", "go to bottom"),
+	),
 }
 
 func (k appKeyMap) ShortHelp() []key.Binding {
 	return []key.Binding{k.Select, k.Back, k.Tab, k.Refresh, k.Quit}
 }
 
+// FullHelp lists every action of keyActions, which names the bindings of
+// appKeys, in columns of minHelpRows.
 func (k appKeyMap) FullHelp() [][]key.Binding {
-	return [][]key.Binding{
-		{k.Select, k.Back, k.Tab, k.ShiftTab},
-		{k.Refresh, k.Help, k.Quit},
+	return helpColumns(minHelpRows)
+}
+
+// minHelpRows is the fewest rows the full help is laid out in. The help
+// view adds rows until the columns fit the terminal width.
+const minHelpRows = 6
+
+// helpColumns lays the keyActions bindings out in columns of rows, in the
+// order the [keys] section documents them.
+func helpColumns(rows int) [][]key.Binding {
+	var cols [][]key.Binding
+	for i := 0; i < len(keyActions); i += rows {
+		var col []key.Binding
+		for _, a := range keyActions[i:min(i+rows, len(keyActions))] {
+			col = append(col, *a.binding)
+		}
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+// keyActions names the bindings for the [keys] section of the config
+// file.
+var keyActions = []struct {
+	name    string
+	binding *key.Binding
+}{
+	{"quit", &appKeys.Quit},
+	{"back", &appKeys.Back},
+	{"select", &appKeys.Select},
+	{"next_tab", &appKeys.Tab},
+	{"prev_tab", &appKeys.ShiftTab},
+	{"refresh", &appKeys.Refresh},
+	{"help", &appKeys.Help},
+	{"reload_token", &appKeys.ReloadToken},
+	{"favourite", &appKeys.Favourite},
+	{"expand", &appKeys.Expand},
+	{"switch_scope", &appKeys.SwitchScope},
+	{"all_projects", &appKeys.AllProjects},
+	{"dashboard", &appKeys.Dashboard},
+	{"failures", &appKeys.Failures},
+	{"timeline", &appKeys.Timeline},
+	{"mark", &appKeys.Mark},
+	{"compare", &appKeys.Compare},
+	{"compare_with", &appKeys.CompareWith},
+	{"history", &appKeys.History},
+	{"group_by_blueprint", &appKeys.GroupByBlueprint},
+	{"layout", &appKeys.Layout},
+	{"sort", &appKeys.Sort},
+	{"reverse_sort", &appKeys.ReverseSort},
+	{"filter", &appKeys.Filter},
+	{"status_filter", &appKeys.StatusFilter},
+	{"group_by_type", &appKeys.GroupByType},
+	{"search", &appKeys.Search},
+	{"palette", &appKeys.Palette},
+	{"up", &appKeys.Up},
+	{"down", &appKeys.Down},
+	{"left", &appKeys.Left},
+	{"right", &appKeys.Right},
+	{"page_up", &appKeys.PageUp},
+	{"page_down", &appKeys.PageDown},
+	{"half_page_up", &appKeys.HalfPageUp},
+	{"half_page_down", &appKeys.HalfPageDown},
+	{"top", &appKeys.Top},
+	{"bottom", &appKeys.Bottom},
+}
+
+// BindKeys replaces the keys of the actions named in the config file's
+// [keys] section. It reports an unknown action or key, and a key bound to
+// two actions, so a conflict is caught at startup rather than by one
+// action quietly shadowing the other.
+func BindKeys(keys config.Keys) error {
+	for _, name := range keys.Actions() {
+		i := keyActionIndex(name)
+		if i < 0 {
+			names := make([]string, len(keyActions))
+			for j, a := range keyActions {
+				names[j] = a.name
+			}
+			return fmt.Errorf("keys: unknown action %q (known: %s)", name, strings.Join(names, ", "))
+		}
+		for _, k := range keys[name] {
+			if _, ok := keyPress(k); !ok {
+				return fmt.Errorf("keys: unknown key %q for %s", k, name)
+			}
+		}
+		b := keyActions[i].binding
+		b.SetKeys(keys[name]...)
+		b.SetHelp(keyLabel(keys[name][0]), b.Help().Desc)
+	}
+
+	bound := make(map[string]string)
+	for _, a := range keyActions {
+		for _, k := range a.binding.Keys() {
+			if other, ok := bound[k]; ok && other != a.name {
+				return fmt.Errorf("keys: %q is bound to both %s and %s", k, other, a.name)
+			}
+			bound[k] = a.name
+		}
+	}
+	return nil
+}
+
+func keyActionIndex(name string) int {
+	for i, a := range keyActions {
+		if a.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// keyLabel is how help shows a binding key: arrows as arrows, anything
+// else as written.
+func keyLabel(k string) string {
+	switch k {
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	}
+	return k
+}
+
+// matchesWithoutText reports whether msg is one of b's keys that types no
+// text. Where a text input has focus, the overlays and the list filter act
+// only on those, so j, k and q still reach the input.
+func matchesWithoutText(msg tea.KeyPressMsg, b key.Binding) bool {
+	return msg.Text == "" && key.Matches(msg, b)
+}
+
+// withoutText narrows b to its keys that type no text.
+func withoutText(b key.Binding) key.Binding {
+	var keys []string
+	for _, k := range b.Keys() {
+		if msg, ok := keyPress(k); ok && msg.Text == "" && msg.Code != tea.KeySpace {
+			keys = append(keys, k)
+		}
+	}
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(b.Help().Key, b.Help().Desc))
+}
+
+// moveKeys is the up/down hint for the overlays.
+func moveKeys() string {
+	return appKeys.Up.Help().Key + "/" + appKeys.Down.Help().Key
+}
+
+// joinKeys makes one binding of several, for the bubbles key maps that
+// fold a few of ours together.
+func joinKeys(bindings ...key.Binding) key.Binding {
+	var keys []string
+	for _, b := range bindings {
+		keys = append(keys, b.Keys()...)
+	}
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(bindings[0].Help().Key, bindings[0].Help().Desc))
+}
+
+// listKeys, tableKeys and viewportKeys are the bubbles key maps with
+// their navigation taken from appKeys, so rebound keys move them too.
+// The list's own help is never shown, so its help toggles get no keys,
+// and quitting is left to the app, which saves the session first.
+func listKeys() list.KeyMap {
+	km := list.DefaultKeyMap()
+	km.CursorUp = appKeys.Up
+	km.CursorDown = appKeys.Down
+	km.PrevPage = joinKeys(appKeys.Left, appKeys.PageUp, appKeys.HalfPageUp)
+	km.NextPage = joinKeys(appKeys.Right, appKeys.PageDown, appKeys.HalfPageDown)
+	km.GoToStart = appKeys.Top
+	km.GoToEnd = appKeys.Bottom
+	km.Filter = appKeys.Filter
+	km.ClearFilter = appKeys.Back
+	km.CancelWhileFiltering = appKeys.Back
+	km.AcceptWhileFiltering = joinKeys(appKeys.Select, withoutText(appKeys.Tab), withoutText(appKeys.ShiftTab), withoutText(appKeys.Up), withoutText(appKeys.Down))
+	km.ShowFullHelp = key.NewBinding()
+	km.CloseFullHelp = key.NewBinding()
+	km.Quit = appKeys.Quit
+	km.ForceQuit = key.NewBinding()
+	return km
+}
+
+func tableKeys() table.KeyMap {
+	return table.KeyMap{
+		LineUp:       appKeys.Up,
+		LineDown:     appKeys.Down,
+		PageUp:       appKeys.PageUp,
+		PageDown:     appKeys.PageDown,
+		HalfPageUp:   appKeys.HalfPageUp,
+		HalfPageDown: appKeys.HalfPageDown,
+		GotoTop:      appKeys.Top,
+		GotoBottom:   appKeys.Bottom,
+	}
+}
+
+// newViewport is viewport.New with viewportKeys.
+func newViewport(width, height int) viewport.Model {
+	vp := viewport.New(viewport.WithWidth(width), viewport.WithHeight(height))
+	vp.KeyMap = viewportKeys()
+	return vp
+}
+
+func viewportKeys() viewport.KeyMap {
+	return viewport.KeyMap{
+		Up:           appKeys.Up,
+		Down:         appKeys.Down,
+		Left:         appKeys.Left,
+		Right:        appKeys.Right,
+		PageUp:       appKeys.PageUp,
+		PageDown:     appKeys.PageDown,
+		HalfPageUp:   appKeys.HalfPageUp,
+		HalfPageDown: appKeys.HalfPageDown,
+	}
+}
+
+// namedKeys are the keys bindings spell out by name.
+var namedKeys = map[string]rune{
+	"enter":     tea.KeyEnter,
+	"esc":       tea.KeyEscape,
+	"tab":       tea.KeyTab,
+	"space":     tea.KeySpace,
+	"backspace": tea.KeyBackspace,
+	"delete":    tea.KeyDelete,
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"home":      tea.KeyHome,
+	"end":       tea.KeyEnd,
+	"pgup":      tea.KeyPgUp,
+	"pgdown":    tea.KeyPgDown,
+}
+
+// keyPress builds the key press a binding key such as "r", "S" or
+// "ctrl+f" stands for, so the key can be pressed on the user's behalf.
+func keyPress(k string) (tea.KeyPressMsg, bool) {
+	var mod tea.KeyMod
+	name := k
+	for {
+		prefix, rest, ok := strings.Cut(name, "+")
+		if !ok || rest == "" {
+			break
+		}
+		switch prefix {
+		case "ctrl":
+			mod |= tea.ModCtrl
+		case "alt":
+			mod |= tea.ModAlt
+		case "shift":
+			mod |= tea.ModShift
+		default:
+			return tea.KeyPressMsg{}, false
+		}
+		name = rest
+	}
+	if code, ok := namedKeys[name]; ok {
+		return tea.KeyPressMsg{Code: code, Mod: mod}, true
+	}
+	r, size := utf8.DecodeRuneInString(name)
+	if size != len(name) || r == utf8.RuneError {
+		return tea.KeyPressMsg{}, false
+	}
+	msg := tea.KeyPressMsg{Code: r, Mod: mod}
+	if mod == 0 {
+		msg.Text = name
 	}
+	return msg, true
 }
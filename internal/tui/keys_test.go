@@ -0,0 +1,153 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/sanity-labs/blueprints-tui/internal/config"
+)
+
+// restoreKeys puts the default bindings back once the test is done.
+func restoreKeys(t *testing.T) {
+	saved := appKeys
+	t.Cleanup(func() { appKeys = saved })
+}
+
+func TestBindKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		keys config.Keys
+		err  string
+	}{
+		{"defaults", nil, ""},
+		{"rebind", config.Keys{"refresh": {"ctrl+l"}, "down": {"down", "r"}}, ""},
+		{"swap", config.Keys{"timeline": {"T"}, "group_by_type": {"t"}}, ""},
+		{"unknown action", config.Keys{"frobnicate": {"z"}}, `unknown action "frobnicate"`},
+		{"unknown key", config.Keys{"up": {"ctrl+zz"}}, `unknown key "ctrl+zz" for up`},
+		{"unknown named key", config.Keys{"up": {"hyper+k"}}, `unknown key "hyper+k" for up`},
+		{"duplicate across actions", config.Keys{"down": {"r"}}, `"r" is bound to both refresh and down`},
+		{"duplicate between rebinds", config.Keys{"dashboard": {"z"}, "failures": {"z"}}, `"z" is bound to both dashboard and failures`},
+		{"duplicate with navigation", config.Keys{"mark": {"j"}}, `"j" is bound to both mark and down`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restoreKeys(t)
+			err := BindKeys(tt.keys)
+			if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Fatalf("BindKeys() = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestBindKeysRebinds(t *testing.T) {
+	restoreKeys(t)
+	if err := BindKeys(config.Keys{"refresh": {"ctrl+l", "R"}, "up": {"up", "w"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := appKeys.Refresh.Keys(); !slices.Equal(got, []string{"ctrl+l", "R"}) {
+		t.Errorf("refresh keys = %v", got)
+	}
+	if got := appKeys.Refresh.Help(); got.Key != "ctrl+l" || got.Desc != "refresh" {
+		t.Errorf("refresh help = %+v", got)
+	}
+	if got := appKeys.Up.Help().Key; got != "↑" {
+		t.Errorf("up help key = %q, want ↑", got)
+	}
+
+	press := func(k string) tea.KeyPressMsg {
+		msg, ok := keyPress(k)
+		if !ok {
+			t.Fatalf("keyPress(%q) failed", k)
+		}
+		return msg
+	}
+	if !key.Matches(press("ctrl+l"), appKeys.Refresh) || key.Matches(press("r"), appKeys.Refresh) {
+		t.Error("refresh does not follow the rebinding")
+	}
+	// Navigation reaches the bubbles key maps.
+	if !key.Matches(press("w"), tableKeys().LineUp) || !key.Matches(press("w"), listKeys().CursorUp) {
+		t.Error("tables and lists do not move up on w")
+	}
+	// The overlays move on keys that type no text only.
+	if !matchesWithoutText(press("up"), appKeys.Up) || matchesWithoutText(press("w"), appKeys.Up) {
+		t.Error("overlays move on the wrong keys")
+	}
+}
+
+func TestKeyPress(t *testing.T) {
+	tests := []struct {
+		key  string
+		ok   bool
+		want string
+	}{
+		{"r", true, "r"},
+		{"S", true, "S"},
+		{"?", true, "?"},
+		{"ctrl+f", true, "ctrl+f"},
+		{"shift+tab", true, "shift+tab"},
+		{"alt+enter", true, "alt+enter"},
+		{"pgdown", true, "pgdown"},
+		{"space", true, "space"},
+		{"", false, ""},
+		{"ab", false, ""},
+		{"ctrl+zz", false, ""},
+		{"hyper+k", false, ""},
+	}
+	for _, tt := range tests {
+		msg, ok := keyPress(tt.key)
+		if ok != tt.ok {
+			t.Errorf("keyPress(%q) ok = %v, want %v", tt.key, ok, tt.ok)
+			continue
+		}
+		if ok && msg.String() != tt.want {
+			t.Errorf("keyPress(%q).String() = %q, want %q", tt.key, msg.String(), tt.want)
+		}
+	}
+}
+
+func TestFullHelpListsEveryAction(t *testing.T) {
+	restoreKeys(t)
+	if err := BindKeys(config.Keys{"timeline": {"ctrl+t"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, rows := range []int{minHelpRows, 10, len(keyActions)} {
+		var got []string
+		for _, col := range helpColumns(rows) {
+			if len(col) > rows {
+				t.Errorf("column of %d bindings, want at most %d", len(col), rows)
+			}
+			for _, b := range col {
+				got = append(got, b.Help().Desc)
+			}
+		}
+		if len(got) != len(keyActions) {
+			t.Errorf("%d rows: help lists %d actions, want %d", rows, len(got), len(keyActions))
+		}
+	}
+	if cols := helpColumns(len(keyActions)); cols[0][keyActionIndex("timeline")].Help().Key != "ctrl+t" {
+		t.Error("help does not show the rebound key")
+	}
+}
+
+func TestListKeysLeaveTextToFilter(t *testing.T) {
+	km := listKeys()
+	for _, k := range []string{"k", "j", "q", "?"} {
+		msg, _ := keyPress(k)
+		for name, b := range map[string]key.Binding{
+			"accept":     km.AcceptWhileFiltering,
+			"force quit": km.ForceQuit,
+			"show help":  km.ShowFullHelp,
+		} {
+			if key.Matches(msg, b) {
+				t.Errorf("%q triggers the list's %s binding", k, name)
+			}
+		}
+	}
+	if msg, _ := keyPress("down"); !key.Matches(msg, km.AcceptWhileFiltering) {
+		t.Error("down does not accept the filter")
+	}
+}
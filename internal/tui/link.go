@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"fmt"
+	"sync"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+	"github.com/sanity-labs/blueprints-tui/internal/deeplink"
+)
+
+type linkResolvedMsg struct {
+	scope scopeSelectedMsg
+	stack api.Stack
+}
+
+type linkFailedMsg struct {
+	err error
+}
+
+func targetFromLink(l deeplink.Link) *restoreTarget {
+	t := &restoreTarget{
+		stackID:     l.StackID,
+		resourceID:  l.ResourceID,
+		operationID: l.OperationID,
+		fromLink:    true,
+	}
+	if l.OperationID != "" {
+		t.tab = tabOperations
+	}
+	return t
+}
+
+// resolveLink finds the scope and stack a deep link points at. When
+// scopeID is empty every project and organization the token can see is
+// searched; when the link has no stack, the stacks in each scope are
+// searched for the operation or resource.
+func resolveLink(client *api.Client, t restoreTarget, scopeType, scopeID string) tea.Cmd {
+	return func() tea.Msg {
+		var candidates []scopeSelectedMsg
+		if scopeID != "" {
+			candidates = []scopeSelectedMsg{{scopeType: scopeType, scopeID: scopeID, label: scopeID}}
+		} else {
+			projects, err := client.ListProjects()
+			if err != nil {
+				return linkFailedMsg{err: err}
+			}
+			orgs, err := client.ListOrganizations()
+			if err != nil {
+				return linkFailedMsg{err: err}
+			}
+			// Most stacks live in projects, so search those first.
+			for _, p := range projects {
+				candidates = append(candidates, scopeSelectedMsg{scopeType: "project", scopeID: p.ID, label: p.DisplayName})
+			}
+			for _, o := range orgs {
+				candidates = append(candidates, scopeSelectedMsg{scopeType: "organization", scopeID: o.ID, label: o.Name})
+			}
+		}
+
+		var (
+			mu    sync.Mutex
+			found *linkResolvedMsg
+		)
+		done := func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return found != nil
+		}
+		forEachLimit(candidates, maxConcurrentRequests, func(sc scopeSelectedMsg) {
+			if done() {
+				return
+			}
+			stack, ok := findLinkedStack(client.WithScope(sc.scopeType, sc.scopeID), t)
+			if !ok {
+				return
+			}
+			mu.Lock()
+			if found == nil {
+				found = &linkResolvedMsg{scope: sc, stack: stack}
+			}
+			mu.Unlock()
+		})
+
+		if found == nil {
+			what := linkSubject(t)
+			if scopeID != "" {
+				return linkFailedMsg{err: fmt.Errorf("%s not found in %s %s", what, scopeType, scopeID)}
+			}
+			return linkFailedMsg{err: fmt.Errorf("%s not found in any organization or project you can access", what)}
+		}
+		return *found
+	}
+}
+
+func findLinkedStack(c *api.Client, t restoreTarget) (api.Stack, bool) {
+	if t.stackID != "" {
+		stack, err := c.GetStack(t.stackID)
+		return stack, err == nil
+	}
+	stacks, err := c.ListStacks()
+	if err != nil {
+		return api.Stack{}, false
+	}
+	for _, s := range stacks {
+		var err error
+		if t.operationID != "" {
+			_, err = c.GetOperation(s.ID, t.operationID)
+		} else {
+			_, err = c.GetResource(s.ID, t.resourceID)
+		}
+		if err == nil {
+			return s, true
+		}
+	}
+	return api.Stack{}, false
+}
+
+func linkSubject(t restoreTarget) string {
+	switch {
+	case t.stackID != "":
+		return "stack " + t.stackID
+	case t.operationID != "":
+		return "operation " + t.operationID
+	}
+	return "resource " + t.resourceID
+}
+
+// updateLink opens the scope and stack a link resolved to. The scope is
+// opened as if picked, so esc walks back to the picker.
+func (m Model) updateLink(msg tea.Msg) (Model, tea.Cmd) {
+	if m.restore == nil {
+		return m, nil
+	}
+	m.resolve = false
+	switch msg := msg.(type) {
+	case linkResolvedMsg:
+		m.setNotice("")
+		if !m.hasRoute(routeStackList) {
+			if m.currentRoute() != routeScopePicker {
+				m.restore = nil
+				return m, nil
+			}
+			open := m.openScope(msg.scope)
+			m.restore.stackID = msg.stack.ID
+			var cmd tea.Cmd
+			m, cmd = m.updateRestore(restoreStackMsg{stack: msg.stack})
+			return m, tea.Batch(open, cmd)
+		}
+		m.restore.stackID = msg.stack.ID
+		return m.updateRestore(restoreStackMsg{stack: msg.stack})
+
+	case linkFailedMsg:
+		m.restore = nil
+		m.setNotice("Could not open link: " + msg.err.Error())
+	}
+	return m, nil
+}
@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"context"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/spinner"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/sanity-labs/blueprints-tui/internal/auth"
+	"github.com/sanity-labs/blueprints-tui/internal/browser"
+	"github.com/sanity-labs/blueprints-tui/internal/config"
+)
+
+type deviceCodeMsg struct {
+	code auth.DeviceCode
+}
+
+type loginSucceededMsg struct {
+	token string
+}
+
+// loginModel is the first-run screen shown when no token source has a
+// token. It runs the device authorization flow, saves the token to the
+// configured store and hands it back to the app via loginSucceededMsg.
+type loginModel struct {
+	auth    *auth.Client
+	store   config.TokenStore
+	styles  styles
+	spinner spinner.Model
+	code    *auth.DeviceCode
+	err     error
+	height  int
+}
+
+func newLoginModel(apiURL string, store config.TokenStore, s styles) loginModel {
+	sp := spinner.New()
+	sp.Spinner = spinner.Dot
+
+	return loginModel{
+		auth:    auth.NewClient(apiURL),
+		store:   store,
+		styles:  s,
+		spinner: sp,
+	}
+}
+
+func (m loginModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.startLogin())
+}
+
+func (m loginModel) Update(msg tea.Msg) (loginModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		if m.err != nil && key.Matches(msg, appKeys.Refresh) {
+			m.err = nil
+			m.code = nil
+			return m, m.Init()
+		}
+
+	case deviceCodeMsg:
+		m.code = &msg.code
+		return m, tea.Batch(m.openBrowser(msg.code.BrowserURL()), m.pollToken(msg.code))
+
+	case apiErrMsg:
+		m.err = msg.err
+
+	case spinner.TickMsg:
+		if m.err == nil {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+// View returns exactly m.height lines.
+func (m loginModel) View() string {
+	s := m.styles
+	var b string
+	switch {
+	case m.err != nil:
+		b = s.title.Render("Login failed") + "\n\n" + m.err.Error() + "\n\n" + s.muted.Render("Press "+appKeys.Refresh.Help().Key+" to retry")
+	case m.code == nil:
+		b = m.spinner.View() + " Starting login…"
+	default:
+		b = s.title.Render("Log in to Sanity") + "\n\n" +
+			"Open this URL in your browser:\n\n  " + s.headerValue.Render(m.code.BrowserURL()) + "\n\n" +
+			"and confirm the code:\n\n  " + s.tabActive.Render(m.code.UserCode) + "\n\n" +
+			m.spinner.View() + " Waiting for authorization…\n\n" +
+			s.muted.Render("The token will be saved to "+m.store.Name()+".")
+	}
+	return lipgloss.PlaceVertical(m.height, lipgloss.Top, b)
+}
+
+func (m *loginModel) SetSize(w, h int) {
+	m.height = h
+}
+
+func (m loginModel) startLogin() tea.Cmd {
+	return func() tea.Msg {
+		code, err := m.auth.Start(context.Background())
+		if err != nil {
+			return apiErrMsg{err: err}
+		}
+		return deviceCodeMsg{code: code}
+	}
+}
+
+// openBrowser is best effort; the URL stays on screen either way.
+func (m loginModel) openBrowser(url string) tea.Cmd {
+	return func() tea.Msg {
+		_ = browser.Open(url)
+		return nil
+	}
+}
+
+func (m loginModel) pollToken(code auth.DeviceCode) tea.Cmd {
+	return func() tea.Msg {
+		token, err := m.auth.Poll(context.Background(), code)
+		if err != nil {
+			return apiErrMsg{err: err}
+		}
+		if err := m.store.Store(token); err != nil {
+			return apiErrMsg{err: err}
+		}
+		return loginSucceededMsg{token: token}
+	}
+}
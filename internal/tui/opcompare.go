@@ -0,0 +1,301 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"charm.land/bubbles/v2/spinner"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+)
+
+// opCompareLoadedMsg carries both log streams oldest first, with their
+// diff worked out in the fetch so resizing only redraws it.
+type opCompareLoadedMsg struct {
+	logsA, logsB []api.Log
+	logDiff      []diffLine
+	resources    []api.Resource
+}
+
+// opCompareModel compares two operations of a stack: how long each took,
+// which resources each touched and where their log streams diverge. a is
+// the older operation.
+type opCompareModel struct {
+	client   *api.Client
+	stackID  string
+	a, b     api.Operation
+	styles   styles
+	viewport viewport.Model
+	spinner  spinner.Model
+	loading  bool
+	err      error
+	height   int
+
+	data opCompareLoadedMsg
+}
+
+func newOpCompareModel(client *api.Client, stackID string, x, y api.Operation, s styles, width, height int) opCompareModel {
+	if y.CreatedAt.Before(x.CreatedAt) {
+		x, y = y, x
+	}
+	sp := spinner.New()
+	sp.Spinner = spinner.Dot
+	return opCompareModel{
+		client:   client,
+		stackID:  stackID,
+		a:        x,
+		b:        y,
+		styles:   s,
+		viewport: newViewport(width, height),
+		spinner:  sp,
+		loading:  true,
+		height:   height,
+	}
+}
+
+func (m opCompareModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.fetch())
+}
+
+func (m opCompareModel) Update(msg tea.Msg) (opCompareModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case opCompareLoadedMsg:
+		m.loading = false
+		m.data = msg
+		m.viewport.SetContent(m.format())
+		return m, nil
+
+	case apiErrMsg:
+		m.loading = false
+		m.err = msg.err
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.loading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m opCompareModel) View() string {
+	var c string
+	switch {
+	case m.err != nil:
+		c = "Error: " + m.err.Error()
+	case m.loading:
+		c = m.spinner.View() + " Loading operations…"
+	default:
+		c = m.viewport.View()
+	}
+	return lipgloss.PlaceVertical(m.height, lipgloss.Top, c)
+}
+
+func (m *opCompareModel) SetSize(w, h int) {
+	m.height = h
+	m.viewport.SetWidth(w)
+	m.viewport.SetHeight(h)
+	if !m.loading && m.err == nil {
+		m.viewport.SetContent(m.format())
+	}
+}
+
+func (m opCompareModel) format() string {
+	s := m.styles
+	now := time.Now()
+	var b strings.Builder
+
+	opLine := func(tag string, op api.Operation) string {
+		return s.title.Render(tag) + " " + op.ID + "  " + s.statusIndicator(op.Status) + " " +
+			s.statusStyle(op.Status).Render(op.Status) + "  " +
+			s.muted.Render(op.CreatedAt.Format("2006-01-02 15:04")) + "  " +
+			formatDuration(opDuration(op, now))
+	}
+	b.WriteString(opLine("A", m.a) + "\n")
+	b.WriteString(opLine("B", m.b) + "\n")
+
+	delta := opDuration(m.b, now) - opDuration(m.a, now)
+	switch {
+	case delta > 0:
+		b.WriteString(s.muted.Render("B took ") + s.logWarn.Render(formatDuration(delta)+" longer") + "\n")
+	case delta < 0:
+		b.WriteString(s.muted.Render("B took ") + s.statusCompleted.Render(formatDuration(-delta)+" less") + "\n")
+	default:
+		b.WriteString(s.muted.Render("Both took the same time") + "\n")
+	}
+
+	b.WriteString("\n" + s.sectionHead.Render("Resources touched") + "\n")
+	onlyA, both, onlyB := m.touched()
+	list := func(label string, names []string) {
+		if len(names) == 0 {
+			return
+		}
+		b.WriteString(s.muted.Render(label) + " " + strings.Join(names, ", ") + "\n")
+	}
+	if len(onlyA)+len(both)+len(onlyB) == 0 {
+		b.WriteString(s.muted.Render("Neither operation touched a resource.") + "\n")
+	}
+	list("Both:  ", both)
+	list("Only A:", onlyA)
+	list("Only B:", onlyB)
+
+	b.WriteString("\n" + s.sectionHead.Render("Logs") + "\n")
+	b.WriteString(m.formatLogDiff())
+	return b.String()
+}
+
+// touched splits the resources each operation touched into those only A,
+// both and only B touched, by name. A resource counts as touched when its
+// OperationID is the operation or the operation logged about it; the
+// former only holds for the last operation that changed the resource.
+func (m opCompareModel) touched() (onlyA, both, onlyB []string) {
+	names := make(map[string]string, len(m.data.resources))
+	inA, inB := make(map[string]bool), make(map[string]bool)
+	for _, r := range m.data.resources {
+		names[r.ID] = r.Name
+		inA[r.ID] = inA[r.ID] || r.OperationID == m.a.ID
+		inB[r.ID] = inB[r.ID] || r.OperationID == m.b.ID
+	}
+	for _, l := range m.data.logsA {
+		if l.ResourceID != "" {
+			inA[l.ResourceID] = true
+		}
+	}
+	for _, l := range m.data.logsB {
+		if l.ResourceID != "" {
+			inB[l.ResourceID] = true
+		}
+	}
+	name := func(id string) string {
+		if n := names[id]; n != "" {
+			return n
+		}
+		return id
+	}
+	for id, ok := range inA {
+		switch {
+		case ok && inB[id]:
+			both = append(both, name(id))
+		case ok:
+			onlyA = append(onlyA, name(id))
+		}
+	}
+	for id, ok := range inB {
+		if ok && !inA[id] {
+			onlyB = append(onlyB, name(id))
+		}
+	}
+	sort.Strings(onlyA)
+	sort.Strings(both)
+	sort.Strings(onlyB)
+	return onlyA, both, onlyB
+}
+
+// formatLogDiff draws the diff of the two log streams, showing
+// each line's offset from the start of its own operation so runs that
+// happened days apart line up. The first divergence is marked.
+func (m opCompareModel) formatLogDiff() string {
+	s := m.styles
+	la, lb := m.data.logsA, m.data.logsB
+
+	offset := func(l api.Log, op api.Operation) string {
+		return fmt.Sprintf("%7s", "+"+formatDuration(max(l.Timestamp.Sub(op.CreatedAt), 0)))
+	}
+	blank := strings.Repeat(" ", 7)
+	message := func(l api.Log) string {
+		level := l.Level
+		if level == "" {
+			level = "INFO"
+		}
+		return s.logLevelStyle(level).Render(fmt.Sprintf("%-5s", level)) + " " + l.Message
+	}
+
+	var b strings.Builder
+	b.WriteString(s.muted.Render("     A       B") + "\n")
+	diverged := false
+	for _, d := range m.data.logDiff {
+		if d.kind != diffSame && !diverged {
+			diverged = true
+			b.WriteString(s.logWarn.Render("── diverges here ──") + "\n")
+		}
+		switch d.kind {
+		case diffSame:
+			b.WriteString("  " + s.muted.Render(offset(la[d.a], m.a)+" "+offset(lb[d.b], m.b)) + " " + message(la[d.a]) + "\n")
+		case diffRemoved:
+			b.WriteString(s.statusFailed.Render("- ") + s.muted.Render(offset(la[d.a], m.a)+" "+blank) + " " + message(la[d.a]) + "\n")
+		case diffAdded:
+			b.WriteString(s.statusCompleted.Render("+ ") + s.muted.Render(blank+" "+offset(lb[d.b], m.b)) + " " + message(lb[d.b]) + "\n")
+		}
+	}
+	if !diverged {
+		b.WriteString(s.muted.Render("The log streams match.") + "\n")
+	}
+	return b.String()
+}
+
+// diffLogs diffs two log streams, oldest first, by level and message.
+func diffLogs(la, lb []api.Log) []diffLine {
+	key := func(l api.Log) string { return strings.ToUpper(l.Level) + " " + l.Message }
+	ka, kb := make([]string, len(la)), make([]string, len(lb))
+	for i, l := range la {
+		ka[i] = key(l)
+	}
+	for i, l := range lb {
+		kb[i] = key(l)
+	}
+	return diffLines(ka, kb)
+}
+
+// oldestFirst returns logs sorted by timestamp; the API returns them
+// newest first.
+func oldestFirst(logs []api.Log) []api.Log {
+	out := make([]api.Log, len(logs))
+	copy(out, logs)
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Timestamp.Before(out[j].Timestamp)
+	})
+	return out
+}
+
+func (m opCompareModel) fetch() tea.Cmd {
+	client, stackID, a, b := m.client, m.stackID, m.a.ID, m.b.ID
+	return func() tea.Msg {
+		var (
+			wg               sync.WaitGroup
+			msg              opCompareLoadedMsg
+			errA, errB, errR error
+		)
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			msg.logsA, errA = client.ListLogs(api.ListLogsOpts{StackID: stackID, OperationID: a})
+		}()
+		go func() {
+			defer wg.Done()
+			msg.logsB, errB = client.ListLogs(api.ListLogsOpts{StackID: stackID, OperationID: b})
+		}()
+		go func() {
+			defer wg.Done()
+			msg.resources, errR = client.ListResources(stackID)
+		}()
+		wg.Wait()
+		for _, err := range []error{errA, errB, errR} {
+			if err != nil {
+				return apiErrMsg{err: err}
+			}
+		}
+		msg.logsA, msg.logsB = oldestFirst(msg.logsA), oldestFirst(msg.logsB)
+		msg.logDiff = diffLogs(msg.logsA, msg.logsB)
+		return msg
+	}
+}
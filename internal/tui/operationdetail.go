@@ -3,7 +3,9 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/spinner"
 	"charm.land/bubbles/v2/viewport"
 	tea "charm.land/bubbletea/v2"
@@ -12,7 +14,12 @@ import (
 )
 
 type operationLogsLoadedMsg struct {
-	logs []api.Log
+	stackID, operationID string
+	logs                 []api.Log
+}
+
+type operationResourcesLoadedMsg struct {
+	resources []api.Resource
 }
 
 type operationDetailModel struct {
@@ -25,7 +32,14 @@ type operationDetailModel struct {
 	spinner     spinner.Model
 	loadingLogs bool
 	err         error
+	width       int
 	height      int
+
+	// showTimeline swaps the log list for per-resource phases drawn
+	// against the operation's duration.
+	showTimeline  bool
+	resourceNames map[string]string
+	namesFetched  bool
 }
 
 func newOperationDetailModel(client *api.Client, stackID string, op api.Operation, s styles, width, height int) operationDetailModel {
@@ -39,6 +53,7 @@ func newOperationDetailModel(client *api.Client, stackID string, op api.Operatio
 		styles:      s,
 		spinner:     sp,
 		loadingLogs: true,
+		width:       width,
 		height:      height,
 	}
 
@@ -46,7 +61,7 @@ func newOperationDetailModel(client *api.Client, stackID string, op api.Operatio
 	if innerH < 1 {
 		innerH = 1
 	}
-	m.viewport = viewport.New(viewport.WithWidth(width), viewport.WithHeight(innerH))
+	m.viewport = newViewport(width, innerH)
 
 	return m
 }
@@ -58,6 +73,7 @@ func (m operationDetailModel) chromeHeight() int {
 }
 
 func (m *operationDetailModel) SetSize(w, h int) {
+	m.width = w
 	m.height = h
 	innerH := h - m.chromeHeight()
 	if innerH < 1 {
@@ -65,6 +81,9 @@ func (m *operationDetailModel) SetSize(w, h int) {
 	}
 	m.viewport.SetWidth(w)
 	m.viewport.SetHeight(innerH)
+	if m.showTimeline && !m.loadingLogs {
+		m.viewport.SetContent(m.formatTimeline())
+	}
 }
 
 func (m operationDetailModel) Init() tea.Cmd {
@@ -73,11 +92,34 @@ func (m operationDetailModel) Init() tea.Cmd {
 
 func (m operationDetailModel) Update(msg tea.Msg) (operationDetailModel, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		if key.Matches(msg, appKeys.Timeline) {
+			m.showTimeline = !m.showTimeline
+			m.setContent()
+			if m.showTimeline && !m.namesFetched {
+				m.namesFetched = true
+				return m, m.fetchResourceNames()
+			}
+			return m, nil
+		}
+
 	case operationLogsLoadedMsg:
+		if msg.operationID != m.operation.ID {
+			return m, nil
+		}
 		m.loadingLogs = false
 		m.logs = msg.logs
-		m.viewport.SetContent(m.formatLogs(msg.logs))
-		m.viewport.GotoBottom()
+		m.setContent()
+
+	case operationResourcesLoadedMsg:
+		m.resourceNames = make(map[string]string, len(msg.resources))
+		for _, r := range msg.resources {
+			m.resourceNames[r.ID] = r.Name
+		}
+		if m.showTimeline {
+			m.viewport.SetContent(m.formatTimeline())
+		}
+		return m, nil
 
 	case apiErrMsg:
 		m.loadingLogs = false
@@ -143,7 +185,11 @@ func (m operationDetailModel) renderChrome() string {
 	}
 	line2 := s.muted.Render(strings.Join(meta, "  ·  "))
 
-	logsHead := s.sectionHead.Render("Logs")
+	head := "Logs"
+	if m.showTimeline {
+		head = "Timeline"
+	}
+	logsHead := s.sectionHead.Render(head)
 
 	return line1 + "\n" + line2 + "\n\n" + logsHead
 }
@@ -167,6 +213,113 @@ func (m operationDetailModel) formatLogs(logs []api.Log) string {
 	return b.String()
 }
 
+// setContent fills the viewport for the current mode: logs scrolled to
+// the newest entry, or the timeline from the top.
+func (m *operationDetailModel) setContent() {
+	if m.showTimeline {
+		m.viewport.SetContent(m.formatTimeline())
+		m.viewport.GotoTop()
+		return
+	}
+	m.viewport.SetContent(m.formatLogs(m.logs))
+	m.viewport.GotoBottom()
+}
+
+// operationEnd is when the operation finished, or the latest moment it is
+// known to have been running.
+func (m operationDetailModel) operationEnd() time.Time {
+	if m.operation.CompletedAt != nil {
+		return *m.operation.CompletedAt
+	}
+	if isInProgress(m.operation.Status) {
+		return time.Now()
+	}
+	end := m.operation.CreatedAt
+	for _, l := range m.logs {
+		if l.Timestamp.After(end) {
+			end = l.Timestamp
+		}
+	}
+	return end
+}
+
+// formatTimeline draws the operation and each resource phase it logged
+// as bars, followed by the slowest resource and the longest quiet gap.
+func (m operationDetailModel) formatTimeline() string {
+	s := m.styles
+	phases := resourcePhases(m.logs, m.operationEnd())
+	if len(phases) == 0 {
+		return s.muted.Render("No resource activity in the logs of this operation.")
+	}
+	from, to := m.operation.CreatedAt, m.operationEnd()
+	if phases[0].start.Before(from) {
+		from = phases[0].start
+	}
+	running := isInProgress(m.operation.Status)
+
+	bars := []timelineBar{{
+		label: "operation",
+		start: from,
+		end:   to,
+		style: s.statusStyle(m.operation.Status),
+		note:  formatDuration(to.Sub(from)),
+	}}
+	slowest := phases[0]
+	for _, p := range phases {
+		style := s.statusCompleted
+		switch {
+		case p.failed:
+			style = s.statusFailed
+		case running && !p.end.Before(to):
+			style = s.statusInProgress
+		}
+		bars = append(bars, timelineBar{
+			label: m.resourceName(p.resourceID),
+			start: p.start,
+			end:   p.end,
+			style: style,
+			note:  formatDuration(p.end.Sub(p.start)),
+		})
+		if p.end.Sub(p.start) > slowest.end.Sub(slowest.start) {
+			slowest = p
+		}
+	}
+
+	lines := renderTimeline(s, bars, from, to, m.width, "0s", formatDuration(to.Sub(from)))
+	lines = append(lines, "",
+		s.muted.Render("Slowest: ")+m.resourceName(slowest.resourceID)+" "+
+			s.muted.Render(formatDuration(slowest.end.Sub(slowest.start))))
+	if gap, before, ok := longestGap(m.logs); ok {
+		line := s.muted.Render("Longest gap: ") + formatDuration(gap) + s.muted.Render(" after ") + before.Message
+		if before.ResourceID != "" {
+			line += s.muted.Render(" (" + m.resourceName(before.ResourceID) + ")")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m operationDetailModel) resourceName(id string) string {
+	if name := m.resourceNames[id]; name != "" {
+		return name
+	}
+	return id
+}
+
+// fetchResourceNames loads the stack's resources to label timeline rows.
+// Failure is not an error; rows fall back to resource IDs.
+func (m operationDetailModel) fetchResourceNames() tea.Cmd {
+	client, stackID := m.client, m.stackID
+	return func() tea.Msg {
+		resources, err := client.ListResources(stackID)
+		if err != nil {
+			client.Debugf("listing resources for timeline: %s", err)
+			return nil
+		}
+		return operationResourcesLoadedMsg{resources: resources}
+	}
+}
+
 func (m operationDetailModel) fetchLogs() tea.Cmd {
 	return func() tea.Msg {
 		logs, err := m.client.ListLogs(api.ListLogsOpts{
@@ -175,6 +328,6 @@ func (m operationDetailModel) fetchLogs() tea.Cmd {
 		if err != nil {
 			return apiErrMsg{err: err}
 		}
-		return operationLogsLoadedMsg{logs: logs}
+		return operationLogsLoadedMsg{stackID: m.stackID, operationID: m.operation.ID, logs: logs}
 	}
 }
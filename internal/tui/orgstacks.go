@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+)
+
+// projectStacks is one project's share of an organization-wide stack
+// listing. err is set when the project could not be listed, e.g. because
+// the token has no access to it.
+type projectStacks struct {
+	project api.Project
+	client  *api.Client
+	stacks  []api.Stack
+	err     error
+}
+
+// listProjectStacks lists the stacks of every project in orgID, at most
+// maxConcurrentRequests projects at a time. A project that fails does not
+// fail the others; its error is returned in its entry.
+func listProjectStacks(client *api.Client, orgID string) ([]projectStacks, error) {
+	projects, err := client.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+	var inOrg []api.Project
+	for _, p := range projects {
+		if p.OrganizationID == orgID {
+			inOrg = append(inOrg, p)
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		results []projectStacks
+	)
+	forEachLimit(inOrg, maxConcurrentRequests, func(p api.Project) {
+		pc := client.WithScope("project", p.ID)
+		stacks, err := pc.ListStacks()
+		mu.Lock()
+		results = append(results, projectStacks{project: p, client: pc, stacks: stacks, err: err})
+		mu.Unlock()
+	})
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].project.DisplayName < results[j].project.DisplayName
+	})
+	return results, nil
+}
+
+// projectErrText is the short inline form of a per-project failure.
+func projectErrText(err error) string {
+	switch {
+	case api.IsStatus(err, http.StatusForbidden):
+		return "no access to this project"
+	case api.IsStatus(err, http.StatusNotFound):
+		return "project not found"
+	}
+	return err.Error()
+}
@@ -0,0 +1,431 @@
+package tui
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/sahilm/fuzzy"
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+	"github.com/sanity-labs/blueprints-tui/internal/browser"
+)
+
+// maxPaletteRows caps how many items the palette shows at once.
+const maxPaletteRows = 12
+
+// paletteItem is one entry of the palette: a command, or a stack to go
+// to. msg is sent when it is picked.
+type paletteItem struct {
+	label string
+	// hint is shown after the label: a command's key binding or where a
+	// stack lives.
+	hint string
+	msg  tea.Msg
+}
+
+type paletteItems []paletteItem
+
+func (p paletteItems) String(i int) string { return p[i].label }
+func (p paletteItems) Len() int            { return len(p) }
+
+// paletteModel is the overlay opened with : or ctrl+p. It fuzzy-matches
+// the commands available on the current route, or the stacks loaded so
+// far once "Go to stack" is picked.
+type paletteModel struct {
+	title   string
+	input   textinput.Model
+	styles  styles
+	items   paletteItems
+	matches paletteItems
+	cursor  int
+	width   int
+}
+
+func newPaletteModel(title, placeholder string, items paletteItems, s styles, width int) paletteModel {
+	ti := textinput.New()
+	ti.Placeholder = placeholder
+	ti.Focus()
+
+	w := min(70, width-4)
+	ti.SetWidth(w - 8)
+	return paletteModel{title: title, input: ti, styles: s, items: items, matches: items, width: w}
+}
+
+// Update returns done=true once the palette should close.
+func (m paletteModel) Update(msg tea.Msg) (paletteModel, tea.Cmd, bool) {
+	if msg, ok := msg.(tea.KeyPressMsg); ok {
+		switch {
+		case key.Matches(msg, appKeys.Back):
+			return m, nil, true
+		case key.Matches(msg, appKeys.Select):
+			if m.cursor >= len(m.matches) {
+				return m, nil, false
+			}
+			picked := m.matches[m.cursor].msg
+			return m, func() tea.Msg { return picked }, true
+		case matchesWithoutText(msg, appKeys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil, false
+		case matchesWithoutText(msg, appKeys.Down):
+			if m.cursor < len(m.matches)-1 {
+				m.cursor++
+			}
+			return m, nil, false
+		}
+	}
+
+	var cmd tea.Cmd
+	prev := m.input.Value()
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != prev {
+		m.filter()
+	}
+	return m, cmd, false
+}
+
+// filter ranks the items against the query, keeping their order when
+// the query is empty.
+func (m *paletteModel) filter() {
+	m.cursor = 0
+	q := strings.TrimSpace(m.input.Value())
+	if q == "" {
+		m.matches = m.items
+		return
+	}
+	found := fuzzy.FindFrom(q, m.items)
+	m.matches = make(paletteItems, len(found))
+	for i, f := range found {
+		m.matches[i] = m.items[f.Index]
+	}
+}
+
+func (m paletteModel) View() string {
+	s := m.styles
+	var b strings.Builder
+	b.WriteString(s.title.Render(m.title) + "\n\n")
+	b.WriteString(m.input.View() + "\n\n")
+
+	if len(m.matches) == 0 {
+		b.WriteString(s.muted.Render("No matches") + "\n")
+	} else {
+		start := 0
+		if m.cursor >= maxPaletteRows {
+			start = m.cursor - maxPaletteRows + 1
+		}
+		end := min(start+maxPaletteRows, len(m.matches))
+		labelWidth := 0
+		for _, it := range m.matches {
+			labelWidth = max(labelWidth, lipgloss.Width(it.label))
+		}
+		for i := start; i < end; i++ {
+			it := m.matches[i]
+			cursor, name := "  ", s.headerValue
+			if i == m.cursor {
+				cursor, name = s.title.Render("▸ "), s.title
+			}
+			line := cursor + name.Render(fmt.Sprintf("%-*s", labelWidth, it.label))
+			if it.hint != "" {
+				line += "  " + s.keycap.Render(it.hint)
+			}
+			b.WriteString(lipgloss.NewStyle().MaxWidth(m.width-6).Render(line) + "\n")
+		}
+		if len(m.matches) > maxPaletteRows {
+			b.WriteString(s.muted.Render(fmt.Sprintf("%d of %d", m.cursor+1, len(m.matches))) + "\n")
+		}
+	}
+
+	b.WriteString("\n" + s.muted.Render(appKeys.Select.Help().Key+" run  ·  "+moveKeys()+" move  ·  "+appKeys.Back.Help().Key+" cancel"))
+	return s.modal.Width(m.width).Render(b.String())
+}
+
+// paletteCommand is a command the palette offers. Commands with a
+// binding run by pressing it, so they do exactly what the key does on the
+// current route; the others run run.
+type paletteCommand struct {
+	name    string
+	binding *key.Binding
+	// when reports whether the command applies to the current route.
+	when func(m Model) bool
+	run  func(m Model) (Model, tea.Cmd)
+}
+
+// paletteCommandMsg runs paletteCommands[index].
+type paletteCommandMsg struct {
+	index int
+}
+
+func onRoutes(routes ...route) func(m Model) bool {
+	return func(m Model) bool { return slices.Contains(routes, m.currentRoute()) }
+}
+
+func onStackTab(tabs ...detailTab) func(m Model) bool {
+	return func(m Model) bool {
+		return m.currentRoute() == routeStackDetail && slices.Contains(tabs, m.stackDetail.activeTab)
+	}
+}
+
+func notLoggingIn(m Model) bool { return m.currentRoute() != routeLogin }
+
+var paletteCommands = []paletteCommand{
+	{
+		name: "Go to stack",
+		when: func(m Model) bool { return len(m.stackEntries()) > 0 },
+		run: func(m Model) (Model, tea.Cmd) {
+			var items paletteItems
+			for _, e := range m.stackEntries() {
+				items = append(items, paletteItem{label: e.stack.Name, hint: e.stack.ID, msg: searchPickedMsg{entry: e}})
+			}
+			m.palette = newPaletteModel("Go to stack", "stack name", items, m.styles, m.effectiveWidth())
+			m.paletteOpen = true
+			return m, nil
+		},
+	},
+	{name: "Switch scope", binding: &appKeys.SwitchScope, when: notLoggingIn},
+	{name: "Refresh", binding: &appKeys.Refresh, when: onRoutes(routeStackList, routeStackDetail, routeDashboard, routeFailures, routeBlueprint)},
+	{name: "Search", binding: &appKeys.Search, when: notLoggingIn},
+	{
+		name: "Toggle theme",
+		when: func(Model) bool { return true },
+		run: func(m Model) (Model, tea.Cmd) {
+			m.dark = !m.dark
+			m.styles = newStyles(m.dark)
+			m.updateChildStyles()
+			return m, nil
+		},
+	},
+	{
+		name: "Copy ID",
+		when: func(m Model) bool { return m.selectedID() != "" },
+		run: func(m Model) (Model, tea.Cmd) {
+			id := m.selectedID()
+			m.setNotice("Copied " + id + " to the clipboard.")
+			return m, tea.SetClipboard(id)
+		},
+	},
+	{
+		name: "Open in browser",
+		when: func(m Model) bool { return m.browserURL() != "" },
+		run: func(m Model) (Model, tea.Cmd) {
+			u := m.browserURL()
+			client := m.client
+			m.setNotice("Opening " + u + "…")
+			return m, func() tea.Msg {
+				if err := browser.Open(u); err != nil {
+					client.Debugf("opening %s: %s", u, err)
+				}
+				return nil
+			}
+		},
+	},
+	{
+		name: "Export logs",
+		when: func(m Model) bool { _, logs := m.exportableLogs(); return len(logs) > 0 },
+		run: func(m Model) (Model, tea.Cmd) {
+			name, logs := m.exportableLogs()
+			return m, exportLogs(name, logs)
+		},
+	},
+	{name: "Dashboard", binding: &appKeys.Dashboard, when: onRoutes(routeStackList)},
+	{name: "Failures", binding: &appKeys.Failures, when: onRoutes(routeStackList, routeDashboard)},
+	{name: "All projects", binding: &appKeys.AllProjects, when: func(m Model) bool {
+		return m.currentRoute() == routeStackList && m.scopeType == "organization"
+	}},
+	{name: "Group by blueprint", binding: &appKeys.GroupByBlueprint, when: onRoutes(routeStackList)},
+	{name: "List or table", binding: &appKeys.Layout, when: onRoutes(routeStackList)},
+	{name: "Compare with another stack", binding: &appKeys.CompareWith, when: onRoutes(routeStackDetail)},
+	{name: "Group resources by type", binding: &appKeys.GroupByType, when: onStackTab(tabResources)},
+	{name: "Operation status filter", binding: &appKeys.StatusFilter, when: onStackTab(tabOperations)},
+	{name: "Toggle timeline", binding: &appKeys.Timeline, when: func(m Model) bool {
+		return onStackTab(tabOperations)(m) || m.currentRoute() == routeOperationDetail
+	}},
+	{name: "Resource history", binding: &appKeys.History, when: onRoutes(routeResourceDetail)},
+	{name: "Help", binding: &appKeys.Help, when: func(Model) bool { return true }},
+	{name: "Quit", binding: &appKeys.Quit, when: func(Model) bool { return true }},
+}
+
+// openPalette lists the commands that apply to the current route.
+func (m Model) openPalette() (Model, tea.Cmd) {
+	var items paletteItems
+	for i, c := range paletteCommands {
+		if !c.when(m) {
+			continue
+		}
+		item := paletteItem{label: c.name, msg: paletteCommandMsg{index: i}}
+		if c.binding != nil {
+			item.hint = c.binding.Help().Key
+		}
+		items = append(items, item)
+	}
+	m.palette = newPaletteModel("Commands", "command", items, m.styles, m.effectiveWidth())
+	m.paletteOpen = true
+	return m, nil
+}
+
+func (m Model) updatePalette(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	var done bool
+	m.palette, cmd, done = m.palette.Update(msg)
+	if done {
+		m.paletteOpen = false
+	}
+	return m, cmd
+}
+
+// runCommand runs a command picked from the palette.
+func (m Model) runCommand(index int) (tea.Model, tea.Cmd) {
+	c := paletteCommands[index]
+	if c.run != nil {
+		return c.run(m)
+	}
+	keys := c.binding.Keys()
+	if len(keys) == 0 {
+		return m, nil
+	}
+	msg, ok := keyPress(keys[0])
+	if !ok {
+		return m, nil
+	}
+	return m.Update(msg)
+}
+
+// stackEntries returns the stacks loaded so far in the scope, by name.
+func (m Model) stackEntries() []searchEntry {
+	seen := make(map[string]bool)
+	var entries []searchEntry
+	for _, b := range m.searchIndex.batches {
+		for _, e := range b.entries {
+			if e.kind == searchStack && !seen[e.stack.ID] {
+				seen[e.stack.ID] = true
+				entries = append(entries, e)
+			}
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b searchEntry) int {
+		return strings.Compare(strings.ToLower(a.stack.Name), strings.ToLower(b.stack.Name))
+	})
+	return entries
+}
+
+// selectedID returns the ID of what the current route shows or has
+// selected: a stack, resource or operation.
+func (m Model) selectedID() string {
+	switch m.currentRoute() {
+	case routeStackList:
+		if st, _, ok := m.stackList.selectedStack(); ok {
+			return st.ID
+		}
+	case routeStackDetail:
+		switch m.stackDetail.activeTab {
+		case tabResources:
+			if r, ok := m.stackDetail.selectedResource(); ok {
+				return r.ID
+			}
+		case tabGraph:
+			if r, ok := m.stackDetail.selectedGraphResource(); ok {
+				return r.ID
+			}
+		case tabOperations:
+			if op, ok := m.stackDetail.selectedOperation(); ok {
+				return op.ID
+			}
+		}
+		return m.stackDetail.stack.ID
+	case routeResourceDetail:
+		return m.resourceDetail.resource.ID
+	case routeOperationDetail:
+		return m.operationDetail.operation.ID
+	case routeBlueprint:
+		if ref, ok := m.blueprint.selected(); ok {
+			return ref.stack.ID
+		}
+	}
+	return ""
+}
+
+// browserURL returns the page to open for the current route: the URL of
+// a resource that has one, such as a function or CORS origin, otherwise
+// the scope's page in sanity.io/manage.
+func (m Model) browserURL() string {
+	if m.currentRoute() == routeResourceDetail {
+		_, fields := resourceCard(m.resourceDetail.displayResource())
+		for _, f := range fields {
+			if f.label == "URL" || f.label == "Origin" {
+				if u, err := url.Parse(f.value); err == nil && (u.Scheme == "https" || u.Scheme == "http") {
+					return f.value
+				}
+			}
+		}
+	}
+	return manageURL(m.scoped())
+}
+
+// manageURL maps the API host to its manage site, api.sanity.io to
+// www.sanity.io/manage, and links the open scope there.
+func manageURL(client *api.Client) string {
+	scopeType, scopeID := client.Scope()
+	u, err := url.Parse(client.APIURL())
+	if err != nil || scopeID == "" || !strings.HasPrefix(u.Host, "api.") {
+		return ""
+	}
+	host := "www." + strings.TrimPrefix(u.Host, "api.")
+	return "https://" + host + "/manage/" + scopeType + "/" + url.PathEscape(scopeID)
+}
+
+// exportableLogs returns the logs on screen, oldest first, and a name
+// for the file they are exported to.
+func (m Model) exportableLogs() (string, []api.Log) {
+	var name string
+	var logs []api.Log
+	switch {
+	case m.currentRoute() == routeOperationDetail:
+		name = m.operationDetail.stackID + "-" + m.operationDetail.operation.ID
+		logs = m.operationDetail.logs
+	case m.currentRoute() == routeStackDetail && m.stackDetail.activeTab == tabLogs:
+		name = m.stackDetail.stack.ID
+		logs = m.stackDetail.logs
+	default:
+		return "", nil
+	}
+	oldest := slices.Clone(logs)
+	slices.Reverse(oldest)
+	return name, oldest
+}
+
+type logsExportedMsg struct {
+	path string
+	n    int
+	err  error
+}
+
+// exportLogs writes logs as plain text to a file in the working
+// directory named after name and the time of the export.
+func exportLogs(name string, logs []api.Log) tea.Cmd {
+	return func() tea.Msg {
+		var b strings.Builder
+		for _, l := range logs {
+			level := l.Level
+			if level == "" {
+				level = "INFO"
+			}
+			fmt.Fprintf(&b, "%s %-5s %s\n", l.Timestamp.Format(time.RFC3339), level, l.Message)
+		}
+		path := fmt.Sprintf("blueprints-%s-%s.log", name, time.Now().Format("20060102-150405"))
+		if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+			return logsExportedMsg{err: err}
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		return logsExportedMsg{path: path, n: len(logs)}
+	}
+}
@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+)
+
+type authRequiredMsg struct{}
+
+type tokenReloadedMsg struct {
+	token  string
+	source string
+}
+
+type reauthErrMsg struct {
+	err error
+}
+
+// reauthModel is the modal shown when the API rejects the token. Requests
+// stay paused inside the client while it is open, so answering it resumes
+// the current view exactly where it was.
+type reauthModel struct {
+	input  textinput.Model
+	client *api.Client
+	reload func() (token, source string, err error)
+	styles styles
+	err    error
+}
+
+func newReauthModel(client *api.Client, reload func() (string, string, error), s styles) reauthModel {
+	ti := textinput.New()
+	ti.Placeholder = "paste a new token"
+	ti.EchoMode = textinput.EchoPassword
+	ti.SetWidth(40)
+	ti.Focus()
+
+	return reauthModel{
+		input:  ti,
+		client: client,
+		reload: reload,
+		styles: s,
+	}
+}
+
+// waitForAuthRequired turns the client's 401 signal into a message. It is
+// re-issued after every prompt so later expiries are caught too.
+func waitForAuthRequired(c *api.Client) tea.Cmd {
+	return func() tea.Msg {
+		<-c.AuthRequired()
+		return authRequiredMsg{}
+	}
+}
+
+// Update returns done=true once the modal should close.
+func (m reauthModel) Update(msg tea.Msg) (reauthModel, tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, appKeys.Back):
+			m.client.AbandonAuth()
+			return m, nil, true
+		case key.Matches(msg, appKeys.Select):
+			token := strings.TrimSpace(m.input.Value())
+			if token == "" {
+				m.err = fmt.Errorf("enter a token or press %s to re-read the token source", appKeys.ReloadToken.Help().Key)
+				return m, nil, false
+			}
+			m.client.SetToken(token)
+			return m, nil, true
+		case key.Matches(msg, appKeys.ReloadToken):
+			if m.reload == nil {
+				m.err = fmt.Errorf("no token source to re-read")
+				return m, nil, false
+			}
+			return m, m.reloadToken(), false
+		}
+
+	case tokenReloadedMsg:
+		if msg.token == m.client.Token() {
+			m.err = fmt.Errorf("%s still has the rejected token", msg.source)
+			return m, nil, false
+		}
+		m.client.SetToken(msg.token)
+		return m, nil, true
+
+	case reauthErrMsg:
+		m.err = msg.err
+		return m, nil, false
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd, false
+}
+
+func (m reauthModel) View() string {
+	s := m.styles
+	var b strings.Builder
+	b.WriteString(s.title.Render("Session expired") + "\n\n")
+	b.WriteString("The API rejected the auth token. Requests are paused\nand will be retried with the new token.\n\n")
+	b.WriteString(m.input.View() + "\n\n")
+	if m.err != nil {
+		b.WriteString(s.statusFailed.Render(m.err.Error()) + "\n\n")
+	}
+	b.WriteString(s.muted.Render(appKeys.Select.Help().Key + " use token  ·  " + appKeys.ReloadToken.Help().Key + " re-read token source  ·  " + appKeys.Back.Help().Key + " give up"))
+	return s.modal.Render(b.String())
+}
+
+func (m reauthModel) reloadToken() tea.Cmd {
+	return func() tea.Msg {
+		token, source, err := m.reload()
+		if err != nil {
+			return reauthErrMsg{err: err}
+		}
+		return tokenReloadedMsg{token: token, source: source}
+	}
+}
+
+// overlay draws box centered over base, which is width×height cells.
+func overlay(base, box string, width, height int) string {
+	x := (width - lipgloss.Width(box)) / 2
+	y := (height - lipgloss.Height(box)) / 2
+	if x < 0 {
+		x = 0
+	}
+	if y < 0 {
+		y = 0
+	}
+	return lipgloss.NewCompositor(
+		lipgloss.NewLayer(base),
+		lipgloss.NewLayer(box).X(x).Y(y).Z(1),
+	).Render()
+}
@@ -0,0 +1,177 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+)
+
+// cardField is one labelled value on a resource summary card.
+type cardField struct {
+	label, value string
+}
+
+// cardRenderer picks out the fields that matter for one kind of resource.
+// Fields whose value is empty are left off the card.
+type cardRenderer struct {
+	title  string
+	fields func(r api.Resource) []cardField
+}
+
+// cardRenderers is keyed by Resource.Type. A type without an entry of its
+// own uses its family's, found by dropping trailing segments, so
+// sanity.function.media renders like the other sanity.function types.
+var cardRenderers = map[string]cardRenderer{
+	"sanity.function": {
+		title: "Function",
+		fields: func(r api.Resource) []cardField {
+			return []cardField{
+				{"Name", resourceValue(r, "name")},
+				{"Source", resourceValue(r, "src")},
+				{"Events", resourceValue(r, "event", "on")},
+				{"Filter", resourceValue(r, "event", "filter")},
+				{"Projection", resourceValue(r, "event", "projection")},
+				{"Memory", withUnit(resourceValue(r, "memory"), "MB")},
+				{"Timeout", withUnit(resourceValue(r, "timeout"), "s")},
+				{"URL", resourceValue(r, "url")},
+			}
+		},
+	},
+	"sanity.project.cors": {
+		title: "CORS origin",
+		fields: func(r api.Resource) []cardField {
+			return []cardField{
+				{"Origin", resourceValue(r, "origin")},
+				{"Credentials", resourceValue(r, "allowCredentials")},
+			}
+		},
+	},
+	"sanity.project.webhook": {
+		title: "Webhook",
+		fields: func(r api.Resource) []cardField {
+			return []cardField{
+				{"URL", resourceValue(r, "url")},
+				{"Method", resourceValue(r, "httpMethod")},
+				{"Dataset", resourceValue(r, "dataset")},
+				{"Events", resourceValue(r, "on")},
+				{"Filter", resourceValue(r, "filter")},
+				{"Projection", resourceValue(r, "projection")},
+				{"Function", resourceValue(r, "function")},
+				{"Enabled", negate(resourceValue(r, "isDisabled"))},
+			}
+		},
+	},
+	"sanity.project.dataset": {
+		title: "Dataset",
+		fields: func(r api.Resource) []cardField {
+			return []cardField{
+				{"Name", resourceValue(r, "datasetName")},
+				{"Name", resourceValue(r, "name")},
+				{"Visibility", resourceValue(r, "aclMode")},
+			}
+		},
+	},
+}
+
+// cardRendererFor returns the renderer for a resource type or its family.
+func cardRendererFor(resourceType string) (cardRenderer, bool) {
+	for t := resourceType; t != ""; {
+		if c, ok := cardRenderers[t]; ok {
+			return c, true
+		}
+		i := strings.LastIndex(t, ".")
+		if i < 0 {
+			break
+		}
+		t = t[:i]
+	}
+	return cardRenderer{}, false
+}
+
+// resourceCard returns the card title and its non-empty fields. A label
+// is used once, so a renderer can list fallbacks for the same field.
+func resourceCard(r api.Resource) (string, []cardField) {
+	c, ok := cardRendererFor(r.Type)
+	if !ok {
+		return "", nil
+	}
+	var fields []cardField
+	seen := make(map[string]bool)
+	for _, f := range c.fields(r) {
+		if f.value == "" || seen[f.label] {
+			continue
+		}
+		seen[f.label] = true
+		fields = append(fields, f)
+	}
+	return c.title, fields
+}
+
+// resourceValue looks a path up in the parameters, then in the provider
+// metadata, and renders what it finds on one line.
+func resourceValue(r api.Resource, path ...string) string {
+	for _, m := range []map[string]any{r.Parameters, r.ProviderMetadata} {
+		if v, ok := lookupPath(m, path); ok {
+			return cardValue(v)
+		}
+	}
+	return ""
+}
+
+func lookupPath(m map[string]any, path []string) (any, bool) {
+	var v any = m
+	for _, p := range path {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[p]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func cardValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, e := range v {
+			parts = append(parts, cardValue(e))
+		}
+		return strings.Join(parts, ", ")
+	}
+	if data, err := json.Marshal(v); err == nil {
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
+
+func withUnit(value, unit string) string {
+	if value == "" {
+		return ""
+	}
+	return value + " " + unit
+}
+
+// negate flips a yes/no value, leaving anything else alone.
+func negate(value string) string {
+	switch value {
+	case "yes":
+		return "no"
+	case "no":
+		return "yes"
+	}
+	return value
+}
@@ -15,6 +15,7 @@ import (
 const resourceHeaderChrome = 3 // name + type + blank line
 
 type resourceLoadedMsg struct {
+	stackID  string
 	resource api.Resource
 }
 
@@ -29,10 +30,14 @@ type resourceDetailModel struct {
 	loading      bool
 	err          error
 	height       int
+
+	showHistory     bool
+	history         resourceHistory
+	historyViewport viewport.Model
 }
 
 func newResourceDetailModel(client *api.Client, stackID string, r api.Resource, s styles, width, height int) resourceDetailModel {
-	vp := viewport.New(viewport.WithWidth(width), viewport.WithHeight(height-resourceHeaderChrome))
+	vp := newViewport(width, height-resourceHeaderChrome)
 	sp := spinner.New()
 	sp.Spinner = spinner.Dot
 
@@ -45,6 +50,8 @@ func newResourceDetailModel(client *api.Client, stackID string, r api.Resource,
 		spinner:  sp,
 		loading:  true,
 		height:   height,
+
+		historyViewport: newViewport(width, height-resourceHeaderChrome),
 	}
 }
 
@@ -52,6 +59,15 @@ func (m *resourceDetailModel) SetSize(w, h int) {
 	m.height = h
 	m.viewport.SetWidth(w)
 	m.viewport.SetHeight(h - resourceHeaderChrome)
+	m.historyViewport.SetWidth(w)
+	if m.showHistory && !m.history.loading {
+		m.setHistoryContent()
+	}
+}
+
+// innerHeight is the height below the name and type.
+func (m resourceDetailModel) innerHeight() int {
+	return max(m.height-resourceHeaderChrome, 1)
 }
 
 func (m resourceDetailModel) Init() tea.Cmd {
@@ -59,8 +75,21 @@ func (m resourceDetailModel) Init() tea.Cmd {
 }
 
 func (m resourceDetailModel) Update(msg tea.Msg) (resourceDetailModel, tea.Cmd) {
+	if m.showHistory {
+		switch msg.(type) {
+		case tea.KeyPressMsg, tea.MouseWheelMsg:
+			return m.updateHistory(msg)
+		}
+	}
+
 	switch msg := msg.(type) {
+	case resourceHistoryMsg:
+		return m.updateHistory(msg)
+
 	case resourceLoadedMsg:
+		if msg.stackID != m.stackID || msg.resource.ID != m.resource.ID {
+			return m, nil
+		}
 		m.loading = false
 		m.fullResource = &msg.resource
 		m.viewport.SetContent(m.formatResource(msg.resource))
@@ -93,17 +122,15 @@ func (m resourceDetailModel) View() string {
 	chrome := s.headerValue.Render(r.Name) + "\n" + s.muted.Render(r.Type) + "\n"
 
 	var inner string
-	if m.loading {
+	switch {
+	case m.showHistory:
+		inner = m.viewHistory()
+	case m.loading:
 		inner = m.spinner.View() + " Loading resource…"
-	} else {
+	default:
 		inner = m.viewport.View()
 	}
-
-	innerH := m.height - resourceHeaderChrome
-	if innerH < 1 {
-		innerH = 1
-	}
-	inner = lipgloss.PlaceVertical(innerH, lipgloss.Top, inner)
+	inner = lipgloss.PlaceVertical(m.innerHeight(), lipgloss.Top, inner)
 
 	return chrome + "\n" + inner
 }
@@ -121,7 +148,7 @@ func (m resourceDetailModel) fetchResource() tea.Cmd {
 		if err != nil {
 			return apiErrMsg{err: err}
 		}
-		return resourceLoadedMsg{resource: r}
+		return resourceLoadedMsg{stackID: m.stackID, resource: r}
 	}
 }
 
@@ -129,6 +156,18 @@ func (m resourceDetailModel) formatResource(r api.Resource) string {
 	s := m.styles
 	var b strings.Builder
 
+	writeRow := func(label, value string) {
+		b.WriteString(fmt.Sprintf("  %s  %s\n", s.muted.Render(fmt.Sprintf("%-14s", label)), value))
+	}
+
+	if title, fields := resourceCard(r); len(fields) > 0 {
+		b.WriteString(s.sectionHead.Render(title) + "\n")
+		for _, f := range fields {
+			writeRow(f.label, f.value)
+		}
+		b.WriteString("\n")
+	}
+
 	b.WriteString(s.sectionHead.Render("Parameters") + "\n")
 	b.WriteString(formatMap(r.Parameters))
 	b.WriteString("\n")
@@ -141,10 +180,6 @@ func (m resourceDetailModel) formatResource(r api.Resource) string {
 
 	b.WriteString(s.muted.Render(strings.Repeat("─", 40)) + "\n\n")
 
-	writeRow := func(label, value string) {
-		b.WriteString(fmt.Sprintf("  %s  %s\n", s.muted.Render(fmt.Sprintf("%-14s", label)), value))
-	}
-
 	writeRow("Created", r.CreatedAt.Format("2006-01-02 15:04"))
 	writeRow("Updated", r.UpdatedAt.Format("2006-01-02 15:04"))
 	if r.ExternalID != "" {
@@ -0,0 +1,237 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+	"github.com/sanity-labs/blueprints-tui/internal/history"
+)
+
+// historyRows caps how many versions are listed above the diff.
+const historyRows = 6
+
+type resourceHistoryMsg struct {
+	resourceID string
+	versions   []history.Snapshot
+	err        error
+}
+
+// resourceHistory is the History view of a resource: the versions
+// recorded locally, newest first, and a diff between two of them. The
+// selected version is compared with the marked base, or with the version
+// before it when nothing is marked.
+type resourceHistory struct {
+	versions []history.Snapshot
+	cursor   int
+	// base indexes the marked version in versions, or -1.
+	base    int
+	loading bool
+	err     error
+}
+
+// recordResources snapshots fetched resources in the background.
+func recordResources(store *history.Store, client *api.Client, rs ...api.Resource) tea.Cmd {
+	if store == nil || len(rs) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		now := time.Now()
+		for _, r := range rs {
+			if _, err := store.Record(r, now); err != nil {
+				client.Debugf("recording resource %s: %s", r.ID, err)
+			}
+		}
+		return nil
+	}
+}
+
+// toggleHistory switches between the resource and its History view,
+// loading the recorded versions when opening it.
+func (m *resourceDetailModel) toggleHistory(store *history.Store) tea.Cmd {
+	if m.showHistory {
+		m.showHistory = false
+		return nil
+	}
+	m.showHistory = true
+	m.history = resourceHistory{loading: true, base: -1}
+	r := m.displayResource()
+	return func() tea.Msg {
+		if store == nil {
+			return resourceHistoryMsg{resourceID: r.ID, err: fmt.Errorf("resource history is unavailable")}
+		}
+		// The resource on screen is always one of the versions.
+		if _, err := store.Record(r, time.Now()); err != nil {
+			return resourceHistoryMsg{resourceID: r.ID, err: err}
+		}
+		versions, err := store.List(r.ID)
+		return resourceHistoryMsg{resourceID: r.ID, versions: versions, err: err}
+	}
+}
+
+func (m resourceDetailModel) updateHistory(msg tea.Msg) (resourceDetailModel, tea.Cmd) {
+	h := &m.history
+	switch msg := msg.(type) {
+	case resourceHistoryMsg:
+		if msg.resourceID != m.resource.ID {
+			return m, nil
+		}
+		h.loading = false
+		h.err = msg.err
+		// Newest first.
+		h.versions = make([]history.Snapshot, len(msg.versions))
+		for i, v := range msg.versions {
+			h.versions[len(msg.versions)-1-i] = v
+		}
+		m.setHistoryContent()
+		return m, nil
+
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, appKeys.Up):
+			if h.cursor > 0 {
+				h.cursor--
+				m.setHistoryContent()
+			}
+			return m, nil
+		case key.Matches(msg, appKeys.Down):
+			if h.cursor < len(h.versions)-1 {
+				h.cursor++
+				m.setHistoryContent()
+			}
+			return m, nil
+		case key.Matches(msg, appKeys.Mark):
+			if h.base == h.cursor {
+				h.base = -1
+			} else {
+				h.base = h.cursor
+			}
+			m.setHistoryContent()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.historyViewport, cmd = m.historyViewport.Update(msg)
+	return m, cmd
+}
+
+// compared returns the indexes of the old and new version being diffed,
+// with ok false when the selected version has nothing to compare with.
+func (h resourceHistory) compared() (older, newer int, ok bool) {
+	if h.cursor >= len(h.versions) {
+		return 0, 0, false
+	}
+	base := h.base
+	if base < 0 || base == h.cursor {
+		base = h.cursor + 1
+	}
+	if base >= len(h.versions) {
+		return 0, 0, false
+	}
+	// Higher indexes are older.
+	if base < h.cursor {
+		return h.cursor, base, true
+	}
+	return base, h.cursor, true
+}
+
+func (h resourceHistory) label(i int) string {
+	return fmt.Sprintf("v%d", len(h.versions)-i)
+}
+
+// historyListHeight is the fixed height of the version list above the
+// diff: the section heading, the rows and a blank line.
+func (m resourceDetailModel) historyListHeight() int {
+	return 2 + min(max(len(m.history.versions), 1), historyRows) + 1
+}
+
+func (m *resourceDetailModel) setHistoryContent() {
+	m.historyViewport.SetHeight(max(m.innerHeight()-m.historyListHeight(), 1))
+	m.historyViewport.SetContent(m.formatHistoryDiff())
+	m.historyViewport.GotoTop()
+}
+
+// viewHistory renders the version list and the diff viewport.
+func (m resourceDetailModel) viewHistory() string {
+	s := m.styles
+	h := m.history
+	switch {
+	case h.loading:
+		return s.muted.Render("Loading history…")
+	case h.err != nil:
+		return "Error: " + h.err.Error()
+	}
+
+	var rows []string
+	start := 0
+	if h.cursor >= historyRows {
+		start = h.cursor - historyRows + 1
+	}
+	for i := start; i < min(start+historyRows, len(h.versions)); i++ {
+		v := h.versions[i]
+		prefix := "  "
+		if i == h.cursor {
+			prefix = s.title.Render("▸ ")
+		}
+		tag := ""
+		if i == h.base {
+			tag = "  " + s.logWarn.Render("base")
+		}
+		rows = append(rows, prefix+s.headerValue.Render(h.label(i))+"  "+
+			s.muted.Render(fmt.Sprintf("%-16s updated %s  ·  seen %s", v.OperationID,
+				v.UpdatedAt.Format("2006-01-02 15:04"), v.FirstSeen.Format("2006-01-02 15:04")))+tag)
+	}
+	list := lipgloss.PlaceVertical(m.historyListHeight()-2, lipgloss.Top, strings.Join(rows, "\n"))
+	return s.sectionHead.Render(countNoun(len(h.versions), "version", "versions")) + "\n" + list + "\n" + m.historyViewport.View()
+}
+
+// formatHistoryDiff diffs the rendered parameters and provider metadata
+// of the compared versions, line by line.
+func (m resourceDetailModel) formatHistoryDiff() string {
+	s := m.styles
+	h := m.history
+	older, newer, ok := h.compared()
+	if !ok {
+		if len(h.versions) <= 1 {
+			return s.muted.Render("Only one version recorded so far. A version is recorded each time\nthe resource is fetched after a deploy changed it.")
+		}
+		return s.muted.Render("This is the oldest recorded version; mark a newer one with " + appKeys.Mark.Help().Key + " to compare.")
+	}
+
+	a := snapshotLines(h.versions[older])
+	b := snapshotLines(h.versions[newer])
+	var out strings.Builder
+	out.WriteString(s.muted.Render(h.label(older)+" → "+h.label(newer)) + "\n")
+	changed := false
+	for _, d := range diffLines(a, b) {
+		switch d.kind {
+		case diffSame:
+			out.WriteString(s.muted.Render("  "+a[d.a]) + "\n")
+		case diffRemoved:
+			changed = true
+			out.WriteString(s.statusFailed.Render("- "+a[d.a]) + "\n")
+		case diffAdded:
+			changed = true
+			out.WriteString(s.statusCompleted.Render("+ "+b[d.b]) + "\n")
+		}
+	}
+	if !changed {
+		return s.muted.Render(h.label(older)+" → "+h.label(newer)) + "\n" + s.muted.Render("No differences.")
+	}
+	return out.String()
+}
+
+// snapshotLines renders a version the way the resource view does, split
+// into lines for diffing.
+func snapshotLines(v history.Snapshot) []string {
+	text := "Parameters\n" + formatMap(v.Parameters)
+	if len(v.ProviderMetadata) > 0 {
+		text += "Provider Metadata\n" + formatMap(v.ProviderMetadata)
+	}
+	return strings.Split(strings.TrimRight(text, "\n"), "\n")
+}
@@ -0,0 +1,216 @@
+package tui
+
+import (
+	"fmt"
+	"net/http"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+	"github.com/sanity-labs/blueprints-tui/internal/state"
+)
+
+// restoreTarget describes a view stack to rebuild at startup: the stack
+// list, then optionally a stack detail and one of its child views. Each
+// level is fetched before it is pushed, so a stack or operation that no
+// longer exists leaves the user one level up with a notice.
+type restoreTarget struct {
+	stackList       stackListView
+	stackID         string
+	tab             detailTab
+	resourceCursor  int
+	operationCursor int
+	resourceID      string
+	operationID     string
+	// fromLink reports missing levels as not found rather than deleted.
+	fromLink bool
+}
+
+func targetFromSession(s state.Session) *restoreTarget {
+	tab := detailTab(s.Tab)
+	if tab < 0 || tab >= tabCount {
+		tab = tabResources
+	}
+	return &restoreTarget{
+		stackList: stackListView{
+			filter:      s.StackFilter,
+			selectedID:  s.SelectedStackID,
+			layout:      s.Layout,
+			grouped:     s.Grouped,
+			allProjects: s.AllProjects,
+		},
+		stackID:         s.StackID,
+		tab:             tab,
+		resourceCursor:  s.ResourceCursor,
+		operationCursor: s.OperationCursor,
+		resourceID:      s.ResourceID,
+		operationID:     s.OperationID,
+	}
+}
+
+type restoreStackMsg struct {
+	stack api.Stack
+}
+
+type restoreResourceMsg struct {
+	resource api.Resource
+}
+
+type restoreOperationMsg struct {
+	operation api.Operation
+}
+
+type restoreFailedMsg struct {
+	what string
+	id   string
+	err  error
+}
+
+func (m Model) fetchRestoreStack(id string) tea.Cmd {
+	client := m.scoped()
+	return func() tea.Msg {
+		stack, err := client.GetStack(id)
+		if err != nil {
+			return restoreFailedMsg{what: "Stack", id: id, err: err}
+		}
+		return restoreStackMsg{stack: stack}
+	}
+}
+
+func (m Model) fetchRestoreResource(stackID, id string) tea.Cmd {
+	client := m.scoped()
+	return func() tea.Msg {
+		r, err := client.GetResource(stackID, id)
+		if err != nil {
+			return restoreFailedMsg{what: "Resource", id: id, err: err}
+		}
+		return restoreResourceMsg{resource: r}
+	}
+}
+
+func (m Model) fetchRestoreOperation(stackID, id string) tea.Cmd {
+	client := m.scoped()
+	return func() tea.Msg {
+		op, err := client.GetOperation(stackID, id)
+		if err != nil {
+			return restoreFailedMsg{what: "Operation", id: id, err: err}
+		}
+		return restoreOperationMsg{operation: op}
+	}
+}
+
+// updateRestore pushes the next level of m.restore. Results that arrive
+// after the user has navigated elsewhere are dropped.
+func (m Model) updateRestore(msg tea.Msg) (Model, tea.Cmd) {
+	t := m.restore
+	if t == nil {
+		return m, nil
+	}
+	w, h := m.effectiveWidth(), m.contentHeight()
+
+	switch msg := msg.(type) {
+	case restoreStackMsg:
+		if m.currentRoute() != routeStackList {
+			m.restore = nil
+			return m, nil
+		}
+		m.stackDetail = newStackDetailModel(m.scoped(), msg.stack, m.styles, w, h)
+		tabCmd := m.stackDetail.restoreView(t.tab, t.resourceCursor, t.operationCursor)
+		m.nav = append(m.nav, routeStackDetail)
+		cmds := []tea.Cmd{m.stackDetail.Init(), tabCmd}
+		switch {
+		case t.resourceID != "":
+			cmds = append(cmds, m.fetchRestoreResource(msg.stack.ID, t.resourceID))
+		case t.operationID != "":
+			cmds = append(cmds, m.fetchRestoreOperation(msg.stack.ID, t.operationID))
+		default:
+			m.restore = nil
+		}
+		return m, tea.Batch(cmds...)
+
+	case restoreResourceMsg:
+		m.restore = nil
+		if m.currentRoute() != routeStackDetail || m.stackDetail.stack.ID != t.stackID {
+			return m, nil
+		}
+		m.resourceDetail = newResourceDetailModel(m.scoped(), t.stackID, msg.resource, m.styles, w, h)
+		m.nav = append(m.nav, routeResourceDetail)
+		return m, m.resourceDetail.Init()
+
+	case restoreOperationMsg:
+		m.restore = nil
+		if m.currentRoute() != routeStackDetail || m.stackDetail.stack.ID != t.stackID {
+			return m, nil
+		}
+		m.operationDetail = newOperationDetailModel(m.scoped(), t.stackID, msg.operation, m.styles, w, h)
+		m.nav = append(m.nav, routeOperationDetail)
+		return m, m.operationDetail.Init()
+
+	case restoreFailedMsg:
+		m.restore = nil
+		switch {
+		case api.IsStatus(msg.err, http.StatusNotFound) && t.fromLink:
+			m.setNotice(fmt.Sprintf("%s %s not found.", msg.what, msg.id))
+		case api.IsStatus(msg.err, http.StatusNotFound):
+			m.setNotice(fmt.Sprintf("%s %s no longer exists.", msg.what, msg.id))
+		default:
+			m.setNotice(fmt.Sprintf("Could not reopen %s %s: %s", msg.what, msg.id, msg.err))
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+// session captures the current view stack for the next launch. It returns
+// nil when no scope is open, so the next launch starts at the picker.
+func (m Model) session() *state.Session {
+	scopeType, scopeID := m.scoped().Scope()
+	if scopeID == "" || !m.hasRoute(routeStackList) {
+		return nil
+	}
+	v := m.stackList.view()
+	s := &state.Session{
+		Scope:           state.Scope{Type: scopeType, ID: scopeID, Label: m.scopeLabel},
+		StackFilter:     v.filter,
+		SelectedStackID: v.selectedID,
+		Layout:          v.layout,
+		Grouped:         v.grouped,
+		AllProjects:     v.allProjects,
+	}
+	// Stacks opened from the all-projects view live in another scope and
+	// cannot be reopened from this one.
+	if m.hasRoute(routeStackDetail) && m.stackDetail.client == m.scoped() {
+		s.StackID = m.stackDetail.stack.ID
+		s.Tab = int(m.stackDetail.activeTab)
+		s.ResourceCursor = m.stackDetail.resourceTable.Cursor()
+		s.OperationCursor = m.stackDetail.operationTable.Cursor()
+	}
+	if s.StackID == "" {
+		return s
+	}
+	switch m.currentRoute() {
+	case routeResourceDetail:
+		s.ResourceID = m.resourceDetail.resource.ID
+	case routeOperationDetail:
+		s.OperationID = m.operationDetail.operation.ID
+	}
+	return s
+}
+
+// saveSession records the current view stack and saves the state. Run it
+// ahead of tea.Quit with tea.Sequence so the write finishes first.
+func (m Model) saveSession() tea.Cmd {
+	if m.state == nil {
+		return nil
+	}
+	m.state.Session = m.session()
+	return m.saveState()
+}
+
+func (m Model) hasRoute(r route) bool {
+	for _, n := range m.nav {
+		if n == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+)
+
+// stackRef is a stack together with the client scoped to where it lives,
+// which differs from the open scope in all-projects mode.
+type stackRef struct {
+	stack   api.Stack
+	client  *api.Client
+	project string
+}
+
+// opRef is an operation and the stack it belongs to.
+type opRef struct {
+	op    api.Operation
+	stack stackRef
+}
+
+// loadStackRefs lists the stacks in the open scope, or in every project of
+// the organization when allProjects is set. skipped counts projects that
+// could not be listed.
+func loadStackRefs(client *api.Client, allProjects bool) (refs []stackRef, skipped int, err error) {
+	if !allProjects {
+		stacks, err := client.ListStacks()
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, s := range stacks {
+			refs = append(refs, stackRef{stack: s, client: client})
+		}
+		return refs, 0, nil
+	}
+	_, orgID := client.Scope()
+	projects, err := listProjectStacks(client, orgID)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, ps := range projects {
+		if ps.err != nil {
+			skipped++
+			continue
+		}
+		for _, s := range ps.stacks {
+			refs = append(refs, stackRef{stack: s, client: ps.client, project: ps.project.DisplayName})
+		}
+	}
+	return refs, skipped, nil
+}
+
+// listOperationsAcross runs ListOperations for every stack, at most
+// maxConcurrentRequests at a time, and merges the results newest first.
+// Stacks whose operations cannot be listed are counted in failed.
+func listOperationsAcross(refs []stackRef, opts api.ListOperationsOpts) (ops []opRef, failed int) {
+	var mu sync.Mutex
+	forEachLimit(refs, maxConcurrentRequests, func(ref stackRef) {
+		list, err := ref.client.ListOperations(ref.stack.ID, opts)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			failed++
+			return
+		}
+		for _, op := range list {
+			ops = append(ops, opRef{op: op, stack: ref})
+		}
+	})
+	sortOpsNewestFirst(ops)
+	return ops, failed
+}
+
+// onlyFailed drops operations that are not FAILED, in case the API
+// ignores the status filter.
+func onlyFailed(ops []opRef) []opRef {
+	out := ops[:0]
+	for _, ref := range ops {
+		if strings.EqualFold(ref.op.Status, "FAILED") {
+			out = append(out, ref)
+		}
+	}
+	return out
+}
+
+func sortOpsNewestFirst(ops []opRef) {
+	sort.SliceStable(ops, func(i, j int) bool {
+		return ops[i].op.CreatedAt.After(ops[j].op.CreatedAt)
+	})
+}
+
+// firstErrorLogs fetches the logs of each operation and returns the
+// earliest ERROR or FATAL message per operation ID.
+func firstErrorLogs(ops []opRef) map[string]string {
+	var mu sync.Mutex
+	out := make(map[string]string, len(ops))
+	forEachLimit(ops, maxConcurrentRequests, func(ref opRef) {
+		logs, err := ref.stack.client.ListLogs(api.ListLogsOpts{StackID: ref.stack.stack.ID, OperationID: ref.op.ID})
+		if err != nil {
+			return
+		}
+		if l, ok := firstErrorLog(logs); ok {
+			mu.Lock()
+			out[ref.op.ID] = l.Message
+			mu.Unlock()
+		}
+	})
+	return out
+}
+
+// firstErrorLog returns the earliest ERROR or FATAL entry. The API returns
+// logs newest first.
+func firstErrorLog(logs []api.Log) (api.Log, bool) {
+	for i := len(logs) - 1; i >= 0; i-- {
+		switch strings.ToUpper(logs[i].Level) {
+		case "ERROR", "FATAL":
+			return logs[i], true
+		}
+	}
+	return api.Log{}, false
+}
+
+func isInProgress(status string) bool {
+	switch strings.ToUpper(status) {
+	case "IN_PROGRESS", "IN PROGRESS", "QUEUED":
+		return true
+	}
+	return false
+}
+
+// countNoun renders n with the singular or plural noun: 1 stack, 3 stacks.
+func countNoun(n int, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// formatDuration renders d compactly: 45s, 3m05s, 2h10m.
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// formatAge renders how long before now t was: 40s ago, 5m ago, 3h ago, 2d ago.
+func formatAge(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", max(int(d.Seconds()), 0))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
+
+// opDuration returns how long op ran, or has been running so far.
+func opDuration(op api.Operation, now time.Time) time.Duration {
+	if op.CompletedAt != nil {
+		return op.CompletedAt.Sub(op.CreatedAt)
+	}
+	return now.Sub(op.CreatedAt)
+}
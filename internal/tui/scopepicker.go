@@ -9,6 +9,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/sanity-labs/blueprints-tui/internal/api"
+	"github.com/sanity-labs/blueprints-tui/internal/state"
 )
 
 type scopeSelectedMsg struct {
@@ -17,43 +18,150 @@ type scopeSelectedMsg struct {
 	label     string
 }
 
-type scopeDataMsg struct {
-	orgs     []api.Organization
+func (s scopeSelectedMsg) stateScope() state.Scope {
+	return state.Scope{Type: s.scopeType, ID: s.scopeID, Label: s.label}
+}
+
+type orgsLoadedMsg struct {
+	orgs []api.Organization
+}
+
+type projectsLoadedMsg struct {
 	projects []api.Project
 }
 
+type stackCountMsg struct {
+	projectID string
+	count     int
+	err       error
+}
+
+// pinned marks copies of items shown in the Favourites and Recent
+// sections at the top of the picker. They have no filter value so that
+// filtering only matches the full tree below.
+type pinned string
+
+const (
+	notPinned       pinned = ""
+	pinnedFavourite pinned = "Favourite"
+	pinnedRecent    pinned = "Recent"
+)
+
+func starPrefix(favourite bool) string {
+	if favourite {
+		return "★ "
+	}
+	return ""
+}
+
+func pinnedPrefix(p pinned) string {
+	if p == notPinned {
+		return ""
+	}
+	return string(p) + "  •  "
+}
+
 type orgItem struct {
-	org          api.Organization
+	org api.Organization
+	// projectCount is -1 while projects are still loading.
 	projectCount int
+	favourite    bool
+	pinned       pinned
+	expanded     bool
 }
 
-func (i orgItem) Title() string { return i.org.Name }
+func (i orgItem) Title() string {
+	marker := ""
+	if i.pinned == notPinned {
+		marker = "▸ "
+		if i.expanded {
+			marker = "▾ "
+		}
+	}
+	return marker + starPrefix(i.favourite) + i.org.Name
+}
 func (i orgItem) Description() string {
-	return fmt.Sprintf("Organization  •  %s  •  %d projects", i.org.ID, i.projectCount)
+	projects := "loading projects…"
+	if i.projectCount >= 0 {
+		projects = fmt.Sprintf("%d projects", i.projectCount)
+	}
+	indent := ""
+	if i.pinned == notPinned {
+		indent = "  "
+	}
+	return indent + pinnedPrefix(i.pinned) + "Organization  •  " + i.org.ID + "  •  " + projects
+}
+func (i orgItem) FilterValue() string {
+	if i.pinned != notPinned {
+		return ""
+	}
+	return i.org.Name + " " + i.org.ID
 }
-func (i orgItem) FilterValue() string { return i.org.Name }
 
 type projectItem struct {
-	project api.Project
+	project   api.Project
+	favourite bool
+	pinned    pinned
+	// stacks is the number of stacks in the project, or -1 until known.
+	stacks int
 }
 
-func (i projectItem) Title() string       { return "    " + i.project.DisplayName }
-func (i projectItem) Description() string { return "    Project  •  " + i.project.ID }
-func (i projectItem) FilterValue() string { return i.project.DisplayName }
+func (i projectItem) indent() string {
+	if i.pinned != notPinned {
+		return ""
+	}
+	return "    "
+}
 
+func (i projectItem) Title() string {
+	return i.indent() + starPrefix(i.favourite) + i.project.DisplayName
+}
+func (i projectItem) Description() string {
+	d := i.indent() + pinnedPrefix(i.pinned) + "Project  •  " + i.project.ID
+	if i.stacks >= 0 {
+		d += fmt.Sprintf("  •  %d stacks", i.stacks)
+	}
+	return d
+}
+func (i projectItem) FilterValue() string {
+	if i.pinned != notPinned {
+		return ""
+	}
+	return i.project.DisplayName + " " + i.project.ID
+}
+
+// scopePickerModel shows organizations as collapsible tree nodes with
+// their projects underneath. Organizations and every project load in
+// parallel up front; stack counts are fetched as projects scroll onto the
+// list's current page.
 type scopePickerModel struct {
-	list    list.Model
-	client  *api.Client
-	styles  styles
-	loading bool
-	spinner spinner.Model
-	err     error
-	height  int
+	list     list.Model
+	client   *api.Client
+	state    *state.State
+	orgs     []api.Organization
+	projects []api.Project
+	styles   styles
+	loading  bool
+	spinner  spinner.Model
+	err      error
+	height   int
+
+	projectsLoaded bool
+	expanded       map[string]bool
+	// stackCounts holds known counts, -1 for projects that could not be
+	// counted; counting records the projects already asked for.
+	stackCounts map[string]int
+	counting    map[string]bool
+	countSem    chan struct{}
+	// all is set while filtering, when every project is listed so that
+	// matches inside collapsed organizations are found.
+	all bool
 }
 
-func newScopePickerModel(client *api.Client, s styles) scopePickerModel {
+func newScopePickerModel(client *api.Client, st *state.State, s styles) scopePickerModel {
 	delegate := list.NewDefaultDelegate()
 	l := list.New(nil, delegate, 0, 0)
+	l.KeyMap = listKeys()
 	l.SetShowTitle(false)
 	l.SetShowStatusBar(true)
 	l.SetFilteringEnabled(true)
@@ -65,25 +173,61 @@ func newScopePickerModel(client *api.Client, s styles) scopePickerModel {
 	sp.Spinner = spinner.Dot
 
 	return scopePickerModel{
-		list:    l,
-		client:  client,
-		styles:  s,
-		loading: true,
-		spinner: sp,
+		list:        l,
+		client:      client,
+		state:       st,
+		styles:      s,
+		loading:     true,
+		spinner:     sp,
+		expanded:    make(map[string]bool),
+		stackCounts: make(map[string]int),
+		counting:    make(map[string]bool),
+		countSem:    make(chan struct{}, maxConcurrentRequests),
 	}
 }
 
 func (m scopePickerModel) Init() tea.Cmd {
-	return tea.Batch(m.spinner.Tick, m.fetchScopeData())
+	return tea.Batch(m.spinner.Tick, m.fetchOrgs(), m.fetchProjects())
 }
 
 func (m scopePickerModel) Update(msg tea.Msg) (scopePickerModel, tea.Cmd) {
 	switch msg := msg.(type) {
-	case scopeDataMsg:
+	case orgsLoadedMsg:
 		m.loading = false
-		items := buildScopeItems(msg.orgs, msg.projects)
-		cmd := m.list.SetItems(items)
-		return m, cmd
+		m.orgs = msg.orgs
+		sort.Slice(m.orgs, func(i, j int) bool {
+			return m.orgs[i].Name < m.orgs[j].Name
+		})
+		if len(m.orgs) == 1 {
+			m.expanded[m.orgs[0].ID] = true
+		}
+		return m, m.rebuild()
+
+	case projectsLoadedMsg:
+		m.projectsLoaded = true
+		m.projects = msg.projects
+		sort.Slice(m.projects, func(i, j int) bool {
+			return m.projects[i].DisplayName < m.projects[j].DisplayName
+		})
+		if m.loading {
+			return m, nil
+		}
+		return m, m.rebuild()
+
+	case stackCountMsg:
+		n := msg.count
+		if msg.err != nil {
+			n = -1
+		}
+		m.stackCounts[msg.projectID] = n
+		var cmds []tea.Cmd
+		for idx, it := range m.list.Items() {
+			if p, ok := it.(projectItem); ok && p.project.ID == msg.projectID {
+				p.stacks = n
+				cmds = append(cmds, m.list.SetItem(idx, p))
+			}
+		}
+		return m, tea.Batch(cmds...)
 
 	case apiErrMsg:
 		m.loading = false
@@ -100,7 +244,13 @@ func (m scopePickerModel) Update(msg tea.Msg) (scopePickerModel, tea.Cmd) {
 	if !m.loading {
 		var cmd tea.Cmd
 		m.list, cmd = m.list.Update(msg)
-		return m, cmd
+		// Entering or leaving the filter switches between the full and
+		// the collapsed tree.
+		if all := m.list.FilterState() != list.Unfiltered; all != m.all {
+			m.all = all
+			cmd = tea.Batch(cmd, m.rebuild())
+		}
+		return m, tea.Batch(cmd, m.countOnScreen())
 	}
 
 	return m, nil
@@ -147,40 +297,182 @@ func (m scopePickerModel) selectedScope() (scopeSelectedMsg, bool) {
 	return scopeSelectedMsg{}, false
 }
 
-func (m scopePickerModel) fetchScopeData() tea.Cmd {
+// toggleFavourite stars or unstars the selected scope and rebuilds the
+// list, keeping the cursor on the same row.
+func (m *scopePickerModel) toggleFavourite() (tea.Cmd, bool) {
+	scope, ok := m.selectedScope()
+	if !ok || m.state == nil {
+		return nil, false
+	}
+	m.state.ToggleFavourite(scope.stateScope())
+	idx := m.list.Index()
+	cmd := m.rebuild()
+	m.list.Select(idx)
+	return cmd, true
+}
+
+// toggleExpanded expands or collapses the selected organization. On a
+// project it collapses the parent organization and moves onto it.
+func (m *scopePickerModel) toggleExpanded() tea.Cmd {
+	if m.all {
+		return nil
+	}
+	var orgID string
+	switch i := m.list.SelectedItem().(type) {
+	case orgItem:
+		if i.pinned != notPinned {
+			return nil
+		}
+		orgID = i.org.ID
+	case projectItem:
+		if i.pinned != notPinned {
+			return nil
+		}
+		orgID = i.project.OrganizationID
+	default:
+		return nil
+	}
+	m.expanded[orgID] = !m.expanded[orgID]
+	cmd := m.rebuild()
+	for idx, it := range m.list.Items() {
+		if o, ok := it.(orgItem); ok && o.pinned == notPinned && o.org.ID == orgID {
+			m.list.Select(idx)
+			break
+		}
+	}
+	return cmd
+}
+
+// rebuild sets the list items from the loaded data and starts counting
+// stacks for the projects on screen.
+func (m *scopePickerModel) rebuild() tea.Cmd {
+	cmd := m.list.SetItems(m.buildItems())
+	return tea.Batch(cmd, m.countOnScreen())
+}
+
+// countOnScreen starts counting stacks for the projects on the list's
+// current page that have not been asked for yet.
+func (m *scopePickerModel) countOnScreen() tea.Cmd {
+	items := m.list.VisibleItems()
+	start, end := m.list.Paginator.GetSliceBounds(len(items))
+	var cmds []tea.Cmd
+	for _, it := range items[start:end] {
+		if p, ok := it.(projectItem); ok && !m.counting[p.project.ID] {
+			m.counting[p.project.ID] = true
+			cmds = append(cmds, m.fetchStackCount(p.project.ID))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m scopePickerModel) fetchOrgs() tea.Cmd {
 	return func() tea.Msg {
 		orgs, err := m.client.ListOrganizations()
 		if err != nil {
 			return apiErrMsg{err: err}
 		}
+		return orgsLoadedMsg{orgs: orgs}
+	}
+}
+
+func (m scopePickerModel) fetchProjects() tea.Cmd {
+	return func() tea.Msg {
 		projects, err := m.client.ListProjects()
 		if err != nil {
 			return apiErrMsg{err: err}
 		}
-		return scopeDataMsg{orgs: orgs, projects: projects}
+		return projectsLoadedMsg{projects: projects}
 	}
 }
 
-func buildScopeItems(orgs []api.Organization, projects []api.Project) []list.Item {
+// fetchStackCount counts the stacks in a project. At most
+// maxConcurrentRequests counts run at once.
+func (m scopePickerModel) fetchStackCount(projectID string) tea.Cmd {
+	client, sem := m.client, m.countSem
+	return func() tea.Msg {
+		sem <- struct{}{}
+		defer func() { <-sem }()
+		stacks, err := client.WithScope("project", projectID).ListStacks()
+		return stackCountMsg{projectID: projectID, count: len(stacks), err: err}
+	}
+}
+
+// maxRecentShown caps the Recent section of the picker.
+const maxRecentShown = 5
+
+// buildItems lists favourites, then recent scopes, then every org
+// followed by its projects when expanded (or always, while filtering).
+// Pinned scopes the token can no longer see are left out.
+func (m scopePickerModel) buildItems() []list.Item {
 	projectsByOrg := make(map[string][]api.Project)
-	for _, p := range projects {
+	for _, p := range m.projects {
 		projectsByOrg[p.OrganizationID] = append(projectsByOrg[p.OrganizationID], p)
 	}
+	projectCount := func(orgID string) int {
+		if !m.projectsLoaded {
+			return -1
+		}
+		return len(projectsByOrg[orgID])
+	}
+	stacks := func(projectID string) int {
+		if n, ok := m.stackCounts[projectID]; ok {
+			return n
+		}
+		return -1
+	}
 
-	sort.Slice(orgs, func(i, j int) bool {
-		return orgs[i].Name < orgs[j].Name
-	})
+	st := m.state
+	isFav := func(scopeType, id string) bool {
+		return st != nil && st.IsFavourite(scopeType, id)
+	}
 
 	var items []list.Item
-	for _, org := range orgs {
-		orgProjects := projectsByOrg[org.ID]
-		items = append(items, orgItem{org: org, projectCount: len(orgProjects)})
+	if st != nil {
+		orgsByID := make(map[string]api.Organization, len(m.orgs))
+		for _, o := range m.orgs {
+			orgsByID[o.ID] = o
+		}
+		projectsByID := make(map[string]api.Project, len(m.projects))
+		for _, p := range m.projects {
+			projectsByID[p.ID] = p
+		}
+		pin := func(sc state.Scope, p pinned) bool {
+			switch sc.Type {
+			case "organization":
+				if o, ok := orgsByID[sc.ID]; ok {
+					items = append(items, orgItem{org: o, projectCount: projectCount(o.ID), favourite: isFav(sc.Type, sc.ID), pinned: p})
+					return true
+				}
+			case "project":
+				if pr, ok := projectsByID[sc.ID]; ok {
+					items = append(items, projectItem{project: pr, favourite: isFav(sc.Type, sc.ID), pinned: p, stacks: stacks(pr.ID)})
+					return true
+				}
+			}
+			return false
+		}
+		for _, sc := range st.Favourites {
+			pin(sc, pinnedFavourite)
+		}
+		shown := 0
+		for _, sc := range st.Recent {
+			if shown == maxRecentShown {
+				break
+			}
+			if !isFav(sc.Type, sc.ID) && pin(sc, pinnedRecent) {
+				shown++
+			}
+		}
+	}
 
-		sort.Slice(orgProjects, func(i, j int) bool {
-			return orgProjects[i].DisplayName < orgProjects[j].DisplayName
-		})
-		for _, p := range orgProjects {
-			items = append(items, projectItem{project: p})
+	for _, org := range m.orgs {
+		expanded := m.all || m.expanded[org.ID]
+		items = append(items, orgItem{org: org, projectCount: projectCount(org.ID), favourite: isFav("organization", org.ID), expanded: expanded})
+		if !expanded {
+			continue
+		}
+		for _, p := range projectsByOrg[org.ID] {
+			items = append(items, projectItem{project: p, favourite: isFav("project", p.ID), stacks: stacks(p.ID)})
 		}
 	}
 
@@ -0,0 +1,278 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/sahilm/fuzzy"
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+	"github.com/sanity-labs/blueprints-tui/internal/state"
+)
+
+// scopeSwitchedMsg is sent when a scope is picked in the switcher. Unlike
+// scopeSelectedMsg it replaces the open scope instead of drilling into one.
+type scopeSwitchedMsg struct {
+	scope scopeSelectedMsg
+}
+
+type switcherDataMsg struct {
+	orgs     []api.Organization
+	projects []api.Project
+	err      error
+}
+
+type scopeEntry struct {
+	scope     scopeSelectedMsg
+	detail    string
+	favourite bool
+}
+
+func (e scopeEntry) filterValue() string {
+	return e.scope.label + " " + e.scope.scopeID
+}
+
+type scopeEntries []scopeEntry
+
+func (s scopeEntries) String(i int) string { return s[i].filterValue() }
+func (s scopeEntries) Len() int            { return len(s) }
+
+// maxSwitcherRows caps how many matches the switcher shows at once.
+const maxSwitcherRows = 10
+
+// scopeSwitcherModel is the overlay opened with S from any route. It
+// fuzzy-matches organizations and projects by name and ID.
+type scopeSwitcherModel struct {
+	input   textinput.Model
+	client  *api.Client
+	state   *state.State
+	styles  styles
+	entries scopeEntries
+	matches scopeEntries
+	cursor  int
+	current string
+	loading bool
+	err     error
+	width   int
+}
+
+func newScopeSwitcherModel(client *api.Client, st *state.State, s styles, width int) scopeSwitcherModel {
+	ti := textinput.New()
+	ti.Placeholder = "organization or project"
+	ti.Focus()
+
+	w := min(70, width-4)
+	ti.SetWidth(w - 8)
+
+	_, current := client.Scope()
+	return scopeSwitcherModel{
+		input:   ti,
+		client:  client,
+		state:   st,
+		styles:  s,
+		current: current,
+		loading: true,
+		width:   w,
+	}
+}
+
+// withData fills the switcher from data the scope picker already loaded.
+func (m scopeSwitcherModel) withData(orgs []api.Organization, projects []api.Project) scopeSwitcherModel {
+	m.loading = false
+	m.entries = buildScopeEntries(orgs, projects, m.state)
+	m.filter()
+	return m
+}
+
+func (m scopeSwitcherModel) Init() tea.Cmd {
+	if !m.loading {
+		return nil
+	}
+	client := m.client
+	return func() tea.Msg {
+		var (
+			wg              sync.WaitGroup
+			msg             switcherDataMsg
+			orgErr, projErr error
+		)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			msg.orgs, orgErr = client.ListOrganizations()
+		}()
+		go func() {
+			defer wg.Done()
+			msg.projects, projErr = client.ListProjects()
+		}()
+		wg.Wait()
+		if orgErr != nil {
+			msg.err = orgErr
+		} else if projErr != nil {
+			msg.err = projErr
+		}
+		return msg
+	}
+}
+
+// Update returns done=true once the switcher should close.
+func (m scopeSwitcherModel) Update(msg tea.Msg) (scopeSwitcherModel, tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case switcherDataMsg:
+		if msg.err != nil {
+			m.loading = false
+			m.err = msg.err
+			return m, nil, false
+		}
+		return m.withData(msg.orgs, msg.projects), nil, false
+
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, appKeys.Back):
+			return m, nil, true
+		case key.Matches(msg, appKeys.Select):
+			if m.cursor >= len(m.matches) {
+				return m, nil, false
+			}
+			sc := m.matches[m.cursor].scope
+			return m, func() tea.Msg { return scopeSwitchedMsg{scope: sc} }, true
+		case matchesWithoutText(msg, appKeys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil, false
+		case matchesWithoutText(msg, appKeys.Down):
+			if m.cursor < len(m.matches)-1 {
+				m.cursor++
+			}
+			return m, nil, false
+		}
+	}
+
+	var cmd tea.Cmd
+	prev := m.input.Value()
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != prev {
+		m.filter()
+	}
+	return m, cmd, false
+}
+
+// filter ranks entries against the query. An empty query keeps the
+// favourites-first order from buildScopeEntries.
+func (m *scopeSwitcherModel) filter() {
+	m.cursor = 0
+	q := strings.TrimSpace(m.input.Value())
+	if q == "" {
+		m.matches = m.entries
+		return
+	}
+	found := fuzzy.FindFrom(q, m.entries)
+	m.matches = make(scopeEntries, len(found))
+	for i, f := range found {
+		m.matches[i] = m.entries[f.Index]
+	}
+}
+
+func (m scopeSwitcherModel) View() string {
+	s := m.styles
+	var b strings.Builder
+	b.WriteString(s.title.Render("Switch scope") + "\n\n")
+	b.WriteString(m.input.View() + "\n\n")
+
+	switch {
+	case m.err != nil:
+		b.WriteString(s.statusFailed.Render(m.err.Error()) + "\n")
+	case m.loading:
+		b.WriteString(s.muted.Render("Loading organizations…") + "\n")
+	case len(m.matches) == 0:
+		b.WriteString(s.muted.Render("No matching scopes") + "\n")
+	default:
+		start := 0
+		if m.cursor >= maxSwitcherRows {
+			start = m.cursor - maxSwitcherRows + 1
+		}
+		end := min(start+maxSwitcherRows, len(m.matches))
+		for i := start; i < end; i++ {
+			b.WriteString(m.renderEntry(m.matches[i], i == m.cursor) + "\n")
+		}
+		if len(m.matches) > maxSwitcherRows {
+			b.WriteString(s.muted.Render(fmt.Sprintf("%d of %d", m.cursor+1, len(m.matches))) + "\n")
+		}
+	}
+
+	b.WriteString("\n" + s.muted.Render(appKeys.Select.Help().Key+" switch  ·  "+moveKeys()+" move  ·  "+appKeys.Back.Help().Key+" cancel"))
+	return s.modal.Width(m.width).Render(b.String())
+}
+
+func (m scopeSwitcherModel) renderEntry(e scopeEntry, selected bool) string {
+	s := m.styles
+	cursor := "  "
+	name := s.headerValue
+	if selected {
+		cursor = s.title.Render("▸ ")
+		name = s.title
+	}
+	label := starPrefix(e.favourite) + e.scope.label
+	if e.scope.scopeID == m.current {
+		label += " (current)"
+	}
+	line := cursor + name.Render(label) + "  " + s.muted.Render(e.detail)
+	return lipgloss.NewStyle().MaxWidth(m.width - 6).Render(line)
+}
+
+// buildScopeEntries lists favourites, then recent scopes, then every org
+// and project by name.
+func buildScopeEntries(orgs []api.Organization, projects []api.Project, st *state.State) scopeEntries {
+	orgNames := make(map[string]string, len(orgs))
+	for _, o := range orgs {
+		orgNames[o.ID] = o.Name
+	}
+
+	var all scopeEntries
+	for _, o := range orgs {
+		all = append(all, scopeEntry{
+			scope:  scopeSelectedMsg{scopeType: "organization", scopeID: o.ID, label: o.Name},
+			detail: "Organization  •  " + o.ID,
+		})
+	}
+	for _, p := range projects {
+		detail := "Project  •  " + p.ID
+		if name := orgNames[p.OrganizationID]; name != "" {
+			detail += "  •  " + name
+		}
+		all = append(all, scopeEntry{
+			scope:  scopeSelectedMsg{scopeType: "project", scopeID: p.ID, label: p.DisplayName},
+			detail: detail,
+		})
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return strings.ToLower(all[i].scope.label) < strings.ToLower(all[j].scope.label)
+	})
+	if st == nil {
+		return all
+	}
+
+	rank := func(e scopeEntry) int {
+		if st.IsFavourite(e.scope.scopeType, e.scope.scopeID) {
+			return 0
+		}
+		for _, r := range st.Recent {
+			if r.Type == e.scope.scopeType && r.ID == e.scope.scopeID {
+				return 1
+			}
+		}
+		return 2
+	}
+	for i := range all {
+		all[i].favourite = st.IsFavourite(all[i].scope.scopeType, all[i].scope.scopeID)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return rank(all[i]) < rank(all[j])
+	})
+	return all
+}
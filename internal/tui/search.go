@@ -0,0 +1,569 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+)
+
+const (
+	// maxSearchLogBatches is how many loads of logs, stack or operation,
+	// the search index keeps. Older ones are dropped first.
+	maxSearchLogBatches = 5
+	// maxSearchRows caps how many results the search shows at once.
+	maxSearchRows = 10
+)
+
+type searchKind int
+
+const (
+	searchStack searchKind = iota
+	searchResource
+	searchLog
+)
+
+func (k searchKind) String() string {
+	switch k {
+	case searchStack:
+		return "stack"
+	case searchResource:
+		return "resource"
+	case searchLog:
+		return "log"
+	}
+	return ""
+}
+
+// searchField is one piece of text a search entry is matched against.
+// Matches in heavier fields rank higher.
+type searchField struct {
+	label  string
+	value  string
+	weight int
+}
+
+// searchEntry is something the search can jump to: a stack, a resource
+// of a stack, or a log line of a stack or one of its operations.
+type searchEntry struct {
+	kind     searchKind
+	client   *api.Client
+	stack    api.Stack
+	resource api.Resource
+	log      api.Log
+	// op is the operation a log line belongs to, when it was loaded from
+	// the operation.
+	op     *api.Operation
+	fields []searchField
+}
+
+// key identifies what the entry jumps to, so the same resource loaded
+// twice is listed once.
+func (e searchEntry) key() string {
+	switch e.kind {
+	case searchResource:
+		return "resource:" + e.resource.ID
+	case searchLog:
+		return fmt.Sprintf("log:%s:%s:%s", e.log.ID, e.log.Timestamp, e.log.Message)
+	}
+	return "stack:" + e.stack.ID
+}
+
+func (e searchEntry) title() string {
+	switch e.kind {
+	case searchResource:
+		return e.resource.Name
+	case searchLog:
+		return e.stack.Name + "  " + e.log.Timestamp.Format("2006-01-02 15:04:05")
+	}
+	return e.stack.Name
+}
+
+func stackSearchEntry(client *api.Client, st api.Stack, project string) searchEntry {
+	e := searchEntry{kind: searchStack, client: client, stack: st, fields: []searchField{
+		{"name", st.Name, 10},
+		{"id", st.ID, 8},
+		{"blueprint", st.BlueprintID, 5},
+	}}
+	if project != "" {
+		e.fields = append(e.fields, searchField{"project", project, 5})
+	}
+	return e
+}
+
+func resourceSearchEntry(client *api.Client, st api.Stack, r api.Resource) searchEntry {
+	e := searchEntry{kind: searchResource, client: client, stack: st, resource: r, fields: []searchField{
+		{"name", r.Name, 9},
+		{"id", r.ID, 7},
+		{"type", r.Type, 6},
+	}}
+	for _, src := range []struct {
+		label  string
+		values map[string]any
+		weight int
+	}{
+		{"parameters", r.Parameters, 4},
+		{"provider metadata", r.ProviderMetadata, 3},
+	} {
+		flat := make(map[string]string)
+		flattenValue("", src.values, flat)
+		paths := make([]string, 0, len(flat))
+		for p := range flat {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		for _, p := range paths {
+			e.fields = append(e.fields, searchField{src.label, p + " = " + flat[p], src.weight})
+		}
+	}
+	return e
+}
+
+func logSearchEntry(client *api.Client, st api.Stack, op *api.Operation, l api.Log) searchEntry {
+	return searchEntry{kind: searchLog, client: client, stack: st, op: op, log: l, fields: []searchField{
+		{"log", l.Message, 2},
+	}}
+}
+
+// searchBatch is the entries indexed from one load, replaced when the
+// same source loads again.
+type searchBatch struct {
+	source  string
+	logs    bool
+	entries []searchEntry
+}
+
+// searchIndex holds what the TUI has loaded so far in the open scope:
+// stacks, resources and the most recent logs.
+type searchIndex struct {
+	batches []searchBatch
+}
+
+// add replaces the batch from source, keeping only the newest
+// maxSearchLogBatches batches of logs.
+func (ix *searchIndex) add(source string, logs bool, entries []searchEntry) {
+	batches := slices.DeleteFunc(slices.Clone(ix.batches), func(b searchBatch) bool {
+		return b.source == source
+	})
+	batches = append(batches, searchBatch{source: source, logs: logs, entries: entries})
+
+	kept := 0
+	for i := len(batches) - 1; i >= 0; i-- {
+		if !batches[i].logs {
+			continue
+		}
+		kept++
+		if kept > maxSearchLogBatches {
+			batches = slices.Delete(batches, i, i+1)
+		}
+	}
+	ix.batches = batches
+}
+
+// counts returns how many stacks, resources and log lines are indexed.
+func (ix searchIndex) counts() (stacks, resources, logs int) {
+	seen := make(map[string]bool)
+	for _, b := range ix.batches {
+		for _, e := range b.entries {
+			if seen[e.key()] {
+				continue
+			}
+			seen[e.key()] = true
+			switch e.kind {
+			case searchStack:
+				stacks++
+			case searchResource:
+				resources++
+			case searchLog:
+				logs++
+			}
+		}
+	}
+	return stacks, resources, logs
+}
+
+// searchResult is an entry matching the query, with the field that
+// matched best.
+type searchResult struct {
+	entry searchEntry
+	field searchField
+	score int
+}
+
+// search ranks the entries matching every word of the query. An entry
+// scores by its best field: the field's weight, times four for an exact
+// match, three for a prefix and two for any other substring. Later loads
+// win ties, so the newest logs come first.
+func (ix searchIndex) search(query string) []searchResult {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil
+	}
+	best := make(map[string]int)
+	var results []searchResult
+	for i := len(ix.batches) - 1; i >= 0; i-- {
+		for _, e := range ix.batches[i].entries {
+			r, ok := matchEntry(e, words)
+			if !ok {
+				continue
+			}
+			k := e.key()
+			if j, seen := best[k]; seen {
+				if r.score > results[j].score {
+					results[j] = r
+				}
+				continue
+			}
+			best[k] = len(results)
+			results = append(results, r)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+	return results
+}
+
+// matchEntry requires each word to appear in some field and scores the
+// entry by the field matching the whole query best.
+func matchEntry(e searchEntry, words []string) (searchResult, bool) {
+	for _, w := range words {
+		found := false
+		for _, f := range e.fields {
+			if strings.Contains(strings.ToLower(f.value), w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return searchResult{}, false
+		}
+	}
+	query := strings.Join(words, " ")
+	r := searchResult{entry: e}
+	for _, f := range e.fields {
+		value := strings.ToLower(f.value)
+		quality := 1
+		switch {
+		case value == query:
+			quality = 4
+		case strings.HasPrefix(value, query):
+			quality = 3
+		case strings.Contains(value, query):
+			quality = 2
+		case !strings.Contains(value, words[0]):
+			continue
+		}
+		if score := f.weight * quality; score > r.score {
+			r.score = score
+			r.field = f
+		}
+	}
+	return r, true
+}
+
+// searchPickedMsg is sent when a search result is chosen.
+type searchPickedMsg struct {
+	entry searchEntry
+}
+
+// searchModel is the overlay opened with ctrl+f from any route. It
+// searches the index in memory, which a searchCrawl keeps filling while
+// the overlay is open, instead of querying the API per keystroke.
+type searchModel struct {
+	input   textinput.Model
+	styles  styles
+	index   searchIndex
+	results []searchResult
+	cursor  int
+	width   int
+	// status reports the crawl filling the index, if any.
+	status string
+}
+
+func newSearchModel(index searchIndex, status string, s styles, width int) searchModel {
+	ti := textinput.New()
+	ti.Placeholder = "stacks, resources, parameters, logs"
+	ti.Focus()
+
+	w := min(90, width-4)
+	ti.SetWidth(w - 8)
+	return searchModel{input: ti, styles: s, index: index, status: status, width: w}
+}
+
+// withIndex searches index instead, keeping the cursor on the same result
+// when it still matches.
+func (m searchModel) withIndex(index searchIndex, status string) searchModel {
+	m.index = index
+	m.status = status
+	if len(m.results) == 0 && strings.TrimSpace(m.input.Value()) == "" {
+		return m
+	}
+	selected := ""
+	if m.cursor < len(m.results) {
+		selected = m.results[m.cursor].entry.key()
+	}
+	m.results = index.search(m.input.Value())
+	m.cursor = 0
+	for i, r := range m.results {
+		if r.entry.key() == selected {
+			m.cursor = i
+			break
+		}
+	}
+	return m
+}
+
+// Update returns done=true once the search should close.
+func (m searchModel) Update(msg tea.Msg) (searchModel, tea.Cmd, bool) {
+	if msg, ok := msg.(tea.KeyPressMsg); ok {
+		switch {
+		case key.Matches(msg, appKeys.Back):
+			return m, nil, true
+		case key.Matches(msg, appKeys.Select):
+			if m.cursor >= len(m.results) {
+				return m, nil, false
+			}
+			e := m.results[m.cursor].entry
+			return m, func() tea.Msg { return searchPickedMsg{entry: e} }, true
+		case matchesWithoutText(msg, appKeys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil, false
+		case matchesWithoutText(msg, appKeys.Down):
+			if m.cursor < len(m.results)-1 {
+				m.cursor++
+			}
+			return m, nil, false
+		}
+	}
+
+	var cmd tea.Cmd
+	prev := m.input.Value()
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != prev {
+		m.results = m.index.search(m.input.Value())
+		m.cursor = 0
+	}
+	return m, cmd, false
+}
+
+func (m searchModel) View() string {
+	s := m.styles
+	var b strings.Builder
+	b.WriteString(s.title.Render("Search") + "\n\n")
+	b.WriteString(m.input.View() + "\n\n")
+
+	switch {
+	case strings.TrimSpace(m.input.Value()) == "":
+		stacks, resources, logs := m.index.counts()
+		b.WriteString(s.muted.Render(fmt.Sprintf("Searches the %s, %s and %s loaded so far.",
+			countNoun(stacks, "stack", "stacks"),
+			countNoun(resources, "resource", "resources"),
+			countNoun(logs, "log line", "log lines"))) + "\n")
+	case len(m.results) == 0:
+		b.WriteString(s.muted.Render("No matches") + "\n")
+	default:
+		start := 0
+		if m.cursor >= maxSearchRows {
+			start = m.cursor - maxSearchRows + 1
+		}
+		end := min(start+maxSearchRows, len(m.results))
+		for i := start; i < end; i++ {
+			b.WriteString(m.renderResult(m.results[i], i == m.cursor) + "\n")
+		}
+		if len(m.results) > maxSearchRows {
+			b.WriteString(s.muted.Render(fmt.Sprintf("%d of %d", m.cursor+1, len(m.results))) + "\n")
+		}
+	}
+	if m.status != "" {
+		b.WriteString(s.muted.Render(m.status) + "\n")
+	}
+
+	b.WriteString("\n" + s.muted.Render(appKeys.Select.Help().Key+" open  ·  "+moveKeys()+" move  ·  "+appKeys.Back.Help().Key+" cancel"))
+	return s.modal.Width(m.width).Render(b.String())
+}
+
+// renderResult shows the kind of the result, what it is and the field
+// that matched.
+func (m searchModel) renderResult(r searchResult, selected bool) string {
+	s := m.styles
+	cursor := "  "
+	name := s.headerValue
+	if selected {
+		cursor = s.title.Render("▸ ")
+		name = s.title
+	}
+	line := cursor + s.muted.Render(fmt.Sprintf("%-9s", r.entry.kind)) + name.Render(r.entry.title())
+	if r.entry.kind == searchResource {
+		line += s.muted.Render(" in " + r.entry.stack.Name)
+	}
+	if f := r.field; f.label != "name" || r.entry.kind == searchLog {
+		line += "  " + s.muted.Render(f.label+": ") + strings.ReplaceAll(f.value, "\n", " ")
+	}
+	return lipgloss.NewStyle().MaxWidth(m.width - 6).Render(line)
+}
+
+// indexForSearch adds the stacks, resources and logs msg carries to the
+// search index. Resources and logs are filed under the stack their
+// message names, whichever view is open when it lands.
+func (m *Model) indexForSearch(msg tea.Msg) {
+	switch msg := msg.(type) {
+	case stacksLoadedMsg:
+		if msg.client != m.scoped() {
+			return
+		}
+		entries := make([]searchEntry, len(msg.stacks))
+		for i, st := range msg.stacks {
+			entries[i] = stackSearchEntry(msg.client, st, "")
+		}
+		m.searchIndex.add("stacks", false, entries)
+
+	case projectStacksLoadedMsg:
+		if msg.client != m.scoped() {
+			return
+		}
+		for _, p := range msg.projects {
+			entries := make([]searchEntry, len(p.stacks))
+			for i, st := range p.stacks {
+				entries[i] = stackSearchEntry(p.client, st, p.project.DisplayName)
+			}
+			m.searchIndex.add("stacks:"+p.project.ID, false, entries)
+		}
+
+	case dashboardLoadedMsg:
+		m.indexStackRefs("dashboard", msg.stacks)
+
+	case blueprintLoadedMsg:
+		m.indexStackRefs("blueprint:"+m.blueprint.blueprintID, msg.stacks)
+
+	case resourcesLoadedMsg:
+		ref, ok := m.indexedStack(msg.stackID)
+		if !ok {
+			return
+		}
+		entries := make([]searchEntry, len(msg.resources))
+		for i, r := range msg.resources {
+			entries[i] = resourceSearchEntry(ref.client, ref.stack, r)
+		}
+		m.searchIndex.add("resources:"+msg.stackID, false, entries)
+
+	case resourceLoadedMsg:
+		ref, ok := m.indexedStack(msg.stackID)
+		if !ok {
+			return
+		}
+		m.searchIndex.add("resource:"+msg.stackID+":"+msg.resource.ID, false, []searchEntry{
+			resourceSearchEntry(ref.client, ref.stack, msg.resource),
+		})
+
+	case logsLoadedMsg:
+		ref, ok := m.indexedStack(msg.stackID)
+		if !ok {
+			return
+		}
+		var ops []api.Operation
+		if m.hasRoute(routeStackDetail) && m.stackDetail.stack.ID == msg.stackID {
+			ops = append(slices.Clone(m.stackDetail.history), m.stackDetail.operations...)
+		}
+		entries := make([]searchEntry, len(msg.logs))
+		for i, l := range msg.logs {
+			var op *api.Operation
+			if j := slices.IndexFunc(ops, func(o api.Operation) bool { return o.ID == l.OperationID }); j >= 0 {
+				op = &ops[j]
+			}
+			entries[i] = logSearchEntry(ref.client, ref.stack, op, l)
+		}
+		m.searchIndex.add("logs:"+msg.stackID, true, entries)
+
+	case operationLogsLoadedMsg:
+		ref, ok := m.indexedStack(msg.stackID)
+		if !ok {
+			return
+		}
+		var op *api.Operation
+		if d := m.operationDetail; m.hasRoute(routeOperationDetail) && d.operation.ID == msg.operationID {
+			op = &d.operation
+		}
+		entries := make([]searchEntry, len(msg.logs))
+		for i, l := range msg.logs {
+			entries[i] = logSearchEntry(ref.client, ref.stack, op, l)
+		}
+		m.searchIndex.add("logs:"+msg.stackID+":"+msg.operationID, true, entries)
+	}
+}
+
+func (m *Model) indexStackRefs(source string, refs []stackRef) {
+	entries := make([]searchEntry, len(refs))
+	for i, ref := range refs {
+		entries[i] = stackSearchEntry(ref.client, ref.stack, ref.project)
+	}
+	m.searchIndex.add(source, false, entries)
+}
+
+// indexedStack finds the stack with id and the client that reaches it:
+// the open stack detail's, or a stack already in the index. Data for a
+// stack found in neither is left out of the index.
+func (m Model) indexedStack(id string) (stackRef, bool) {
+	if m.hasRoute(routeStackDetail) && m.stackDetail.stack.ID == id {
+		return stackRef{stack: m.stackDetail.displayStack(), client: m.stackDetail.client}, true
+	}
+	for _, b := range m.searchIndex.batches {
+		for _, e := range b.entries {
+			if e.kind == searchStack && e.stack.ID == id {
+				return stackRef{stack: e.stack, client: e.client}, true
+			}
+		}
+	}
+	return stackRef{}, false
+}
+
+// searchResultStackMsg carries the current state of a picked result's
+// stack, or why it could not be fetched.
+type searchResultStackMsg struct {
+	entry searchEntry
+	stack api.Stack
+	err   error
+}
+
+// fetchSearchStack fetches the stack of a picked result before it is
+// opened, since the index may hold a stack that has since changed or gone.
+func fetchSearchStack(e searchEntry) tea.Cmd {
+	return func() tea.Msg {
+		stack, err := e.client.GetStack(e.stack.ID)
+		return searchResultStackMsg{entry: e, stack: stack, err: err}
+	}
+}
+
+// openSearchResult opens what a search result points at with its stack
+// underneath, so esc walks back through the stack. Any stack already open
+// is closed first, since there is one stack detail view.
+func (m *Model) openSearchResult(e searchEntry) tea.Cmd {
+	if i := slices.Index(m.nav, routeStackDetail); i >= 0 {
+		m.nav = m.nav[:i]
+	}
+	if e.kind == searchLog && e.op != nil {
+		return m.openOperation(opRef{op: *e.op, stack: stackRef{stack: e.stack, client: e.client}})
+	}
+
+	w, h := m.effectiveWidth(), m.contentHeight()
+	m.stackDetail = newStackDetailModel(e.client, e.stack, m.styles, w, h)
+	m.nav = append(m.nav, routeStackDetail)
+	cmds := []tea.Cmd{m.stackDetail.Init()}
+	switch e.kind {
+	case searchResource:
+		m.resourceDetail = newResourceDetailModel(e.client, e.stack.ID, e.resource, m.styles, w, h)
+		m.nav = append(m.nav, routeResourceDetail)
+		cmds = append(cmds, m.resourceDetail.Init())
+	case searchLog:
+		cmds = append(cmds, m.stackDetail.restoreView(tabLogs, 0, 0))
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,179 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+)
+
+// searchCrawl fills the search index with the stacks of the open scope
+// and the resources of each, so the search covers more than the views
+// opened so far. It starts the first time the search is opened in a scope
+// and feeds the index one stack at a time, so results fill in as it runs.
+type searchCrawl struct {
+	// id tells this crawl's messages from those of one it replaced.
+	id          int
+	client      *api.Client
+	allProjects bool
+	cancel      context.CancelFunc
+	ch          chan tea.Msg
+
+	running bool
+	// stacks is how many stacks there are to load resources for, -1
+	// until they are listed; loaded and failed count those done.
+	stacks, loaded, failed int
+	err                    error
+}
+
+type crawlStacksMsg struct {
+	id   int
+	refs []stackRef
+	err  error
+}
+
+type crawlResourcesMsg struct {
+	id        int
+	ref       stackRef
+	resources []api.Resource
+	err       error
+}
+
+type crawlDoneMsg struct {
+	id int
+}
+
+// covers reports whether the crawl is for the stacks the list shows.
+func (c searchCrawl) covers(client *api.Client, allProjects bool) bool {
+	return c.client == client && c.allProjects == allProjects
+}
+
+// stop cancels a running crawl and returns an empty one that will not
+// take its messages.
+func (c searchCrawl) stop() searchCrawl {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	return searchCrawl{id: c.id}
+}
+
+// status describes the crawl for the search overlay, or is empty once it
+// has finished without trouble.
+func (c searchCrawl) status() string {
+	switch {
+	case c.err != nil:
+		return "Could not list the stacks in scope: " + c.err.Error()
+	case c.running && c.stacks < 0:
+		return "Listing the stacks in scope…"
+	case c.running:
+		return fmt.Sprintf("Loading resources: %d of %s…", c.loaded+c.failed, countNoun(c.stacks, "stack", "stacks"))
+	case c.failed > 0:
+		return fmt.Sprintf("The resources of %s could not be loaded.", countNoun(c.failed, "stack", "stacks"))
+	}
+	return ""
+}
+
+// startCrawl replaces any crawl with one of the stacks the list shows.
+func (m *Model) startCrawl() tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.crawl = m.crawl.stop()
+	m.crawl = searchCrawl{
+		id:          m.crawl.id + 1,
+		client:      m.scoped(),
+		allProjects: m.stackList.allProjects,
+		cancel:      cancel,
+		ch:          make(chan tea.Msg),
+		running:     true,
+		stacks:      -1,
+	}
+	c := m.crawl
+	return tea.Batch(runCrawl(ctx, c.id, c.client, c.allProjects, c.ch), waitForCrawl(c.id, c.ch))
+}
+
+// runCrawl lists the stacks, then their resources at most
+// maxConcurrentRequests at a time, sending each result on ch. It gives up
+// once ctx is cancelled.
+func runCrawl(ctx context.Context, id int, client *api.Client, allProjects bool, ch chan<- tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		defer close(ch)
+		send := func(msg tea.Msg) {
+			select {
+			case ch <- msg:
+			case <-ctx.Done():
+			}
+		}
+		refs, _, err := loadStackRefs(client, allProjects)
+		send(crawlStacksMsg{id: id, refs: refs, err: err})
+		if err != nil {
+			return nil
+		}
+		forEachLimit(refs, maxConcurrentRequests, func(ref stackRef) {
+			if ctx.Err() != nil {
+				return
+			}
+			resources, err := ref.client.ListResources(ref.stack.ID)
+			send(crawlResourcesMsg{id: id, ref: ref, resources: resources, err: err})
+		})
+		return nil
+	}
+}
+
+// waitForCrawl delivers the next result of crawl id, then crawlDoneMsg
+// once it has finished.
+func waitForCrawl(id int, ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return crawlDoneMsg{id: id}
+		}
+		return msg
+	}
+}
+
+// updateCrawl files a crawl result in the search index and refreshes an
+// open search with it. Results of a replaced crawl are dropped.
+func (m Model) updateCrawl(msg tea.Msg) (Model, tea.Cmd) {
+	c := &m.crawl
+	var next tea.Cmd
+	switch msg := msg.(type) {
+	case crawlStacksMsg:
+		if msg.id != c.id {
+			return m, nil
+		}
+		if msg.err != nil {
+			c.err = msg.err
+		} else {
+			c.stacks = len(msg.refs)
+			m.indexStackRefs("crawl", msg.refs)
+		}
+		next = waitForCrawl(c.id, c.ch)
+
+	case crawlResourcesMsg:
+		if msg.id != c.id {
+			return m, nil
+		}
+		if msg.err != nil {
+			c.failed++
+		} else {
+			c.loaded++
+			entries := make([]searchEntry, len(msg.resources))
+			for i, r := range msg.resources {
+				entries[i] = resourceSearchEntry(msg.ref.client, msg.ref.stack, r)
+			}
+			m.searchIndex.add("resources:"+msg.ref.stack.ID, false, entries)
+		}
+		next = waitForCrawl(c.id, c.ch)
+
+	case crawlDoneMsg:
+		if msg.id != c.id {
+			return m, nil
+		}
+		c.running = false
+		c.cancel()
+	}
+	if m.searching {
+		m.search = m.search.withIndex(m.searchIndex, c.status())
+	}
+	return m, next
+}
@@ -0,0 +1,600 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/list"
+	"charm.land/bubbles/v2/spinner"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+)
+
+// Environment is an API endpoint other than the one the TUI was started
+// against. Stacks can be compared across environments.
+type Environment struct {
+	Name   string
+	APIURL string
+	// Connect returns a client for the environment, resolving its token
+	// on first use. It is called off the UI goroutine.
+	Connect func() (*api.Client, error)
+}
+
+// compareSide is one of the two stacks in a stack comparison.
+type compareSide struct {
+	env string
+	ref stackRef
+}
+
+func (c compareSide) label() string {
+	name := c.ref.stack.Name
+	if c.ref.project != "" {
+		name = c.ref.project + " / " + name
+	}
+	return name + "  " + c.env
+}
+
+type envConnectedMsg struct {
+	env    string
+	client *api.Client
+	err    error
+}
+
+type compareStacksLoadedMsg struct {
+	env  string
+	refs []stackRef
+}
+
+type stackComparePickedMsg struct {
+	other compareSide
+}
+
+type envItem struct {
+	env Environment
+}
+
+func (i envItem) Title() string       { return i.env.Name }
+func (i envItem) Description() string { return i.env.APIURL }
+func (i envItem) FilterValue() string { return i.env.Name + " " + i.env.APIURL }
+
+type compareStackItem struct {
+	ref           stackRef
+	sameBlueprint bool
+}
+
+func (i compareStackItem) Title() string {
+	if i.ref.project == "" {
+		return i.ref.stack.Name
+	}
+	return i.ref.project + " / " + i.ref.stack.Name
+}
+
+func (i compareStackItem) Description() string {
+	desc := i.ref.stack.ID + "  ·  " + i.ref.stack.BlueprintID
+	if i.sameBlueprint {
+		desc += "  ·  same blueprint"
+	}
+	return desc
+}
+
+func (i compareStackItem) FilterValue() string {
+	return i.ref.project + " " + i.ref.stack.Name + " " + i.ref.stack.ID + " " + i.ref.stack.BlueprintID
+}
+
+// stackPickerModel picks the stack to compare the open stack with: first
+// the environment, when more than one is configured, then any stack the
+// token can see there. Stacks of the same blueprint are listed first.
+type stackPickerModel struct {
+	list    list.Model
+	envs    []Environment
+	stack   api.Stack
+	styles  styles
+	spinner spinner.Model
+	loading bool
+	err     error
+	height  int
+
+	// env is the chosen environment; empty while choosing one.
+	env string
+}
+
+func newStackPickerModel(stack api.Stack, envs []Environment, s styles, width, height int) stackPickerModel {
+	l := list.New(nil, list.NewDefaultDelegate(), width, height-1)
+	l.KeyMap = listKeys()
+	l.SetShowTitle(false)
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(true)
+	l.SetShowHelp(false)
+	l.SetShowPagination(false)
+	l.Styles.TitleBar = lipgloss.NewStyle()
+
+	sp := spinner.New()
+	sp.Spinner = spinner.Dot
+
+	m := stackPickerModel{
+		list:    l,
+		envs:    envs,
+		stack:   stack,
+		styles:  s,
+		spinner: sp,
+		height:  height,
+	}
+	if len(envs) == 1 {
+		m.env = envs[0].Name
+		m.loading = true
+		return m
+	}
+	items := make([]list.Item, len(envs))
+	for i, e := range envs {
+		items[i] = envItem{env: e}
+	}
+	m.list.SetItems(items)
+	return m
+}
+
+func (m stackPickerModel) Init() tea.Cmd {
+	if m.env == "" {
+		return nil
+	}
+	return tea.Batch(m.spinner.Tick, m.connect(m.envs[0]))
+}
+
+func (m stackPickerModel) Update(msg tea.Msg) (stackPickerModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		if key.Matches(msg, appKeys.Select) && !m.filtering() && !m.loading {
+			switch item := m.list.SelectedItem().(type) {
+			case envItem:
+				m.env = item.env.Name
+				m.loading = true
+				m.err = nil
+				m.list.ResetFilter()
+				m.list.SetItems(nil)
+				return m, tea.Batch(m.spinner.Tick, m.connect(item.env))
+			case compareStackItem:
+				other := compareSide{env: m.env, ref: item.ref}
+				return m, func() tea.Msg { return stackComparePickedMsg{other: other} }
+			}
+			return m, nil
+		}
+
+	case envConnectedMsg:
+		if msg.env != m.env {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.loading = false
+			m.err = msg.err
+			return m, nil
+		}
+		return m, m.fetchStacks(msg.env, msg.client)
+
+	case compareStacksLoadedMsg:
+		if msg.env != m.env {
+			return m, nil
+		}
+		m.loading = false
+		m.setStacks(msg.refs)
+		return m, nil
+
+	case apiErrMsg:
+		m.loading = false
+		m.err = msg.err
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.loading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// back returns to the environment list, reporting false when there is
+// no earlier step to return to.
+func (m stackPickerModel) back() (stackPickerModel, bool) {
+	if m.env == "" || len(m.envs) == 1 {
+		return m, false
+	}
+	m.env = ""
+	m.loading = false
+	m.err = nil
+	m.list.ResetFilter()
+	items := make([]list.Item, len(m.envs))
+	for i, e := range m.envs {
+		items[i] = envItem{env: e}
+	}
+	m.list.SetItems(items)
+	return m, true
+}
+
+func (m stackPickerModel) filtering() bool {
+	return m.list.FilterState() == list.Filtering
+}
+
+// setStacks lists every stack but the one being compared, same blueprint
+// first, then by project and name.
+func (m *stackPickerModel) setStacks(refs []stackRef) {
+	var items []compareStackItem
+	for _, ref := range refs {
+		if ref.stack.ID == m.stack.ID && m.env == m.envs[0].Name {
+			continue
+		}
+		items = append(items, compareStackItem{ref: ref, sameBlueprint: ref.stack.BlueprintID == m.stack.BlueprintID})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].sameBlueprint != items[j].sameBlueprint {
+			return items[i].sameBlueprint
+		}
+		return strings.ToLower(items[i].Title()) < strings.ToLower(items[j].Title())
+	})
+	listItems := make([]list.Item, len(items))
+	for i, it := range items {
+		listItems[i] = it
+	}
+	m.list.SetItems(listItems)
+	m.list.Select(0)
+}
+
+func (m stackPickerModel) View() string {
+	s := m.styles
+	title := "Compare " + m.stack.Name + " with…"
+	var c string
+	switch {
+	case m.err != nil:
+		c = s.title.Render("Error") + "\n\n" + m.err.Error()
+	case m.loading:
+		c = m.spinner.View() + " Loading stacks in " + m.env + "…"
+	case len(m.list.Items()) == 0:
+		c = s.muted.Render("No other stacks found.")
+	default:
+		return s.title.Render(title) + "\n" + m.list.View()
+	}
+	return lipgloss.PlaceVertical(m.height, lipgloss.Top, s.title.Render(title)+"\n\n"+c)
+}
+
+func (m *stackPickerModel) SetSize(w, h int) {
+	m.height = h
+	m.list.SetSize(w, h-1)
+}
+
+func (m stackPickerModel) connect(env Environment) tea.Cmd {
+	return func() tea.Msg {
+		client, err := env.Connect()
+		return envConnectedMsg{env: env.Name, client: client, err: err}
+	}
+}
+
+func (m stackPickerModel) fetchStacks(env string, client *api.Client) tea.Cmd {
+	return func() tea.Msg {
+		refs, err := listAllStacks(client)
+		if err != nil {
+			return apiErrMsg{err: err}
+		}
+		return compareStacksLoadedMsg{env: env, refs: refs}
+	}
+}
+
+// listAllStacks lists the stacks of every organization and project the
+// client's token can see. Scopes that cannot be listed are skipped.
+func listAllStacks(client *api.Client) ([]stackRef, error) {
+	orgs, err := client.ListOrganizations()
+	if err != nil {
+		return nil, err
+	}
+	projects, err := client.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+	type scope struct{ typ, id, label string }
+	var scopes []scope
+	for _, o := range orgs {
+		scopes = append(scopes, scope{"organization", o.ID, o.Name})
+	}
+	for _, p := range projects {
+		scopes = append(scopes, scope{"project", p.ID, p.DisplayName})
+	}
+
+	var (
+		mu   sync.Mutex
+		refs []stackRef
+	)
+	forEachLimit(scopes, maxConcurrentRequests, func(sc scope) {
+		c := client.WithScope(sc.typ, sc.id)
+		stacks, err := c.ListStacks()
+		if err != nil {
+			client.Debugf("listing stacks in %s %s: %s", sc.typ, sc.id, err)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, st := range stacks {
+			refs = append(refs, stackRef{stack: st, client: c, project: sc.label})
+		}
+	})
+	return refs, nil
+}
+
+// envHost names an environment by its API host.
+func envHost(apiURL string) string {
+	if u, err := url.Parse(apiURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return apiURL
+}
+
+type stackCompareLoadedMsg struct {
+	a, b []api.Resource
+}
+
+// resourceChange is a resource present in both stacks whose parameters
+// differ.
+type resourceChange struct {
+	name, typ string
+	diff      []paramDiff
+}
+
+// paramDiff is one flattened parameter path that differs. A missing
+// side is empty with its has flag unset.
+type paramDiff struct {
+	path       string
+	a, b       string
+	hasA, hasB bool
+}
+
+// stackCompareModel compares the resources of two stacks, usually the
+// same blueprint deployed to two projects or environments. Resources are
+// matched by name and type.
+type stackCompareModel struct {
+	a, b     compareSide
+	styles   styles
+	viewport viewport.Model
+	spinner  spinner.Model
+	loading  bool
+	err      error
+	height   int
+
+	data stackCompareLoadedMsg
+}
+
+func newStackCompareModel(a, b compareSide, s styles, width, height int) stackCompareModel {
+	sp := spinner.New()
+	sp.Spinner = spinner.Dot
+	return stackCompareModel{
+		a:        a,
+		b:        b,
+		styles:   s,
+		viewport: newViewport(width, height),
+		spinner:  sp,
+		loading:  true,
+		height:   height,
+	}
+}
+
+func (m stackCompareModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.fetch())
+}
+
+func (m stackCompareModel) Update(msg tea.Msg) (stackCompareModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case stackCompareLoadedMsg:
+		m.loading = false
+		m.data = msg
+		m.viewport.SetContent(m.format())
+		return m, nil
+
+	case apiErrMsg:
+		m.loading = false
+		m.err = msg.err
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.loading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m stackCompareModel) View() string {
+	var c string
+	switch {
+	case m.err != nil:
+		c = "Error: " + m.err.Error()
+	case m.loading:
+		c = m.spinner.View() + " Loading resources…"
+	default:
+		c = m.viewport.View()
+	}
+	return lipgloss.PlaceVertical(m.height, lipgloss.Top, c)
+}
+
+func (m *stackCompareModel) SetSize(w, h int) {
+	m.height = h
+	m.viewport.SetWidth(w)
+	m.viewport.SetHeight(h)
+}
+
+// compare matches the resources of both stacks by name and type.
+func (m stackCompareModel) compare() (removed, added []api.Resource, changed []resourceChange, same int) {
+	key := func(r api.Resource) string { return r.Name + "\x00" + r.Type }
+	inB := make(map[string]api.Resource, len(m.data.b))
+	for _, r := range m.data.b {
+		inB[key(r)] = r
+	}
+	matched := make(map[string]bool)
+	for _, ra := range m.data.a {
+		rb, ok := inB[key(ra)]
+		if !ok {
+			removed = append(removed, ra)
+			continue
+		}
+		matched[key(ra)] = true
+		if d := diffParams(ra.Parameters, rb.Parameters); len(d) > 0 {
+			changed = append(changed, resourceChange{name: ra.Name, typ: ra.Type, diff: d})
+		} else {
+			same++
+		}
+	}
+	for _, rb := range m.data.b {
+		if !matched[key(rb)] {
+			added = append(added, rb)
+		}
+	}
+	byName := func(rs []api.Resource) {
+		sort.Slice(rs, func(i, j int) bool { return rs[i].Name < rs[j].Name })
+	}
+	byName(removed)
+	byName(added)
+	sort.Slice(changed, func(i, j int) bool { return changed[i].name < changed[j].name })
+	return removed, added, changed, same
+}
+
+func (m stackCompareModel) format() string {
+	s := m.styles
+	var b strings.Builder
+	b.WriteString(s.title.Render("A") + " " + m.a.label() + "\n")
+	b.WriteString(s.title.Render("B") + " " + m.b.label() + "\n\n")
+
+	removed, added, changed, same := m.compare()
+	b.WriteString(strings.Join([]string{
+		s.statusFailed.Render(fmt.Sprintf("%d only in A", len(removed))),
+		s.statusCompleted.Render(fmt.Sprintf("%d only in B", len(added))),
+		s.logWarn.Render(fmt.Sprintf("%d changed", len(changed))),
+		s.muted.Render(fmt.Sprintf("%d identical", same)),
+	}, "   ") + "\n")
+
+	if len(changed) > 0 {
+		b.WriteString("\n" + s.sectionHead.Render("Changed") + "\n")
+		for _, c := range changed {
+			b.WriteString(s.headerValue.Render(c.name) + "  " + s.muted.Render(c.typ) + "\n")
+			for _, d := range c.diff {
+				switch {
+				case !d.hasB:
+					b.WriteString(s.statusFailed.Render("  - "+d.path+": "+d.a) + "\n")
+				case !d.hasA:
+					b.WriteString(s.statusCompleted.Render("  + "+d.path+": "+d.b) + "\n")
+				default:
+					b.WriteString(s.logWarn.Render("  ~ "+d.path+": ") +
+						s.statusFailed.Render(d.a) + s.muted.Render(" → ") + s.statusCompleted.Render(d.b) + "\n")
+				}
+			}
+		}
+	}
+	section := func(title string, rs []api.Resource, mark string, style lipgloss.Style) {
+		if len(rs) == 0 {
+			return
+		}
+		b.WriteString("\n" + s.sectionHead.Render(title) + "\n")
+		for _, r := range rs {
+			b.WriteString(style.Render(mark+" "+r.Name) + "  " + s.muted.Render(r.Type) + "\n")
+		}
+	}
+	section("Only in A", removed, "-", s.statusFailed)
+	section("Only in B", added, "+", s.statusCompleted)
+	return b.String()
+}
+
+// diffParams compares two parameter maps path by path.
+func diffParams(a, b map[string]any) []paramDiff {
+	fa, fb := make(map[string]string), make(map[string]string)
+	flattenValue("", a, fa)
+	flattenValue("", b, fb)
+	paths := make(map[string]bool, len(fa)+len(fb))
+	for p := range fa {
+		paths[p] = true
+	}
+	for p := range fb {
+		paths[p] = true
+	}
+	var out []paramDiff
+	for p := range paths {
+		va, okA := fa[p]
+		vb, okB := fb[p]
+		if okA && okB && va == vb {
+			continue
+		}
+		out = append(out, paramDiff{path: p, a: va, b: vb, hasA: okA, hasB: okB})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].path < out[j].path })
+	return out
+}
+
+// flattenValue writes every leaf of v into out keyed by its path, such as
+// event.on[0]. Leaves are JSON-encoded; empty objects and arrays are
+// leaves too so that they still show up in a diff.
+func flattenValue(path string, v any, out map[string]string) {
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) == 0 && path != "" {
+			out[path] = "{}"
+		}
+		for k, child := range v {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			flattenValue(p, child, out)
+		}
+	case []any:
+		if len(v) == 0 {
+			out[path] = "[]"
+		}
+		for i, child := range v {
+			flattenValue(fmt.Sprintf("%s[%d]", path, i), child, out)
+		}
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			out[path] = fmt.Sprint(v)
+			return
+		}
+		out[path] = string(data)
+	}
+}
+
+func (m stackCompareModel) fetch() tea.Cmd {
+	a, b := m.a.ref, m.b.ref
+	return func() tea.Msg {
+		var (
+			wg         sync.WaitGroup
+			msg        stackCompareLoadedMsg
+			errA, errB error
+		)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			msg.a, errA = a.client.ListResources(a.stack.ID)
+		}()
+		go func() {
+			defer wg.Done()
+			msg.b, errB = b.client.ListResources(b.stack.ID)
+		}()
+		wg.Wait()
+		if errA != nil {
+			return apiErrMsg{err: errA}
+		}
+		if errB != nil {
+			return apiErrMsg{err: errB}
+		}
+		return msg
+	}
+}
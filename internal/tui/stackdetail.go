@@ -2,7 +2,9 @@ package tui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/spinner"
@@ -19,6 +21,8 @@ const (
 	tabResources detailTab = iota
 	tabOperations
 	tabLogs
+	tabStats
+	tabGraph
 	tabCount
 )
 
@@ -30,6 +34,10 @@ func (t detailTab) String() string {
 		return "Operations"
 	case tabLogs:
 		return "Logs"
+	case tabStats:
+		return "Stats"
+	case tabGraph:
+		return "Graph"
 	}
 	return ""
 }
@@ -38,16 +46,23 @@ type stackLoadedMsg struct {
 	stack api.Stack
 }
 
+// The loaded messages name the stack they were fetched for, so one that
+// lands after the user moved to another stack is not taken for its data.
 type resourcesLoadedMsg struct {
+	stackID   string
 	resources []api.Resource
 }
 
 type operationsLoadedMsg struct {
+	stackID    string
 	operations []api.Operation
+	// status is the status filter the operations were listed with.
+	status string
 }
 
 type logsLoadedMsg struct {
-	logs []api.Log
+	stackID string
+	logs    []api.Log
 }
 
 type stackDetailModel struct {
@@ -59,22 +74,58 @@ type stackDetailModel struct {
 	resources  []api.Resource
 	operations []api.Operation
 	logs       []api.Log
+	// history is every operation, whatever the status chip, for the
+	// Stats tab; operations holds those matching the chip.
+	history []api.Operation
 
 	resourceTable  table.Model
 	operationTable table.Model
 	logViewport    viewport.Model
+	statsViewport  viewport.Model
 	spinner        spinner.Model
 
 	loadingStack      bool
 	loadingResources  bool
 	loadingOperations bool
+	loadingHistory    bool
 	loadingLogs       bool
 	resourcesLoaded   bool
 	operationsLoaded  bool
+	historyLoaded     bool
 	logsLoaded        bool
 	err               error
 	width             int
 	height            int
+
+	// opTimeline draws the Operations tab as bars along the stack's
+	// history instead of a table.
+	opTimeline bool
+
+	// marked holds the IDs of up to two operations picked for comparison.
+	marked []string
+
+	// The tables show the resources and operations matching their
+	// query, in its order; selections index these.
+	resourceQuery   tableQuery
+	operationQuery  tableQuery
+	shownResources  []api.Resource
+	shownOperations []api.Operation
+	// groupByType heads each resource type with its count. resourceRows
+	// maps table rows to shownResources, with -1 for the headings.
+	groupByType  bool
+	resourceRows []int
+	// graph is the references between the resources, drawn as
+	// graphRows on the Graph tab.
+	graph       resourceGraph
+	graphRows   []graphRow
+	graphCursor int
+
+	// statusChip indexes opStatusChips.
+	statusChip int
+
+	// Restored table cursors, applied when the rows first arrive.
+	pendingResourceCursor  int
+	pendingOperationCursor int
 }
 
 // chromeHeight returns the measured height of the non-scrollable region
@@ -106,40 +157,36 @@ func newStackDetailModel(client *api.Client, stack api.Stack, s styles, width, h
 		height:           height,
 		loadingStack:     true,
 		loadingResources: true,
+		resourceQuery:    newTableQuery(),
+		operationQuery:   newTableQuery(),
 	}
 
 	innerH := m.innerHeight()
 
 	rt := table.New(
-		table.WithColumns([]table.Column{
-			{Title: "Name", Width: 30},
-			{Title: "Type", Width: 30},
-			{Title: "ID", Width: 16},
-		}),
+		table.WithColumns(resourceColumns(width)),
 		table.WithFocused(true),
+		table.WithKeyMap(tableKeys()),
 		table.WithWidth(width),
-		table.WithHeight(innerH),
+		table.WithHeight(max(innerH-1, 1)),
 	)
 
 	ot := table.New(
-		table.WithColumns([]table.Column{
-			{Title: " ", Width: 3},
-			{Title: "ID", Width: 16},
-			{Title: "Status", Width: 14},
-			{Title: "Created", Width: 20},
-		}),
+		table.WithColumns(operationColumns(width)),
+		table.WithKeyMap(tableKeys()),
 		table.WithWidth(width),
-		table.WithHeight(innerH),
+		table.WithHeight(max(innerH-1, 1)),
 	)
 
 	rt.SetStyles(s.table)
 	ot.SetStyles(s.table)
 
-	vp := viewport.New(viewport.WithWidth(width), viewport.WithHeight(innerH))
+	vp := newViewport(width, innerH)
 
 	m.resourceTable = rt
 	m.operationTable = ot
 	m.logViewport = vp
+	m.statsViewport = newViewport(width, innerH)
 	return m
 }
 
@@ -154,7 +201,24 @@ func (m stackDetailModel) Init() tea.Cmd {
 func (m stackDetailModel) Update(msg tea.Msg) (stackDetailModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
+		if m.filtering() {
+			return m.updateFilter(msg)
+		}
 		switch {
+		case key.Matches(msg, appKeys.Filter):
+			return m, m.startFilter()
+		case key.Matches(msg, appKeys.Sort):
+			m.sortBy(false)
+			return m, nil
+		case key.Matches(msg, appKeys.ReverseSort):
+			m.sortBy(true)
+			return m, nil
+		case key.Matches(msg, appKeys.StatusFilter) && m.activeTab == tabOperations:
+			return m, m.cycleStatusChip()
+		case key.Matches(msg, appKeys.GroupByType) && m.activeTab == tabResources:
+			m.groupByType = !m.groupByType
+			m.setResourceRows()
+			return m, nil
 		case key.Matches(msg, appKeys.Tab):
 			m.activeTab = (m.activeTab + 1) % tabCount
 			m = m.updateFocus()
@@ -165,38 +229,78 @@ func (m stackDetailModel) Update(msg tea.Msg) (stackDetailModel, tea.Cmd) {
 			return m, m.ensureTabLoaded()
 		case key.Matches(msg, appKeys.Refresh):
 			return m, m.refreshTab()
+		case key.Matches(msg, appKeys.Timeline) && m.activeTab == tabOperations:
+			m.opTimeline = !m.opTimeline
+			return m, nil
+		case key.Matches(msg, appKeys.Mark) && m.activeTab == tabOperations:
+			m.toggleMark()
+			return m, nil
+		}
+		if m.activeTab == tabGraph {
+			switch {
+			case key.Matches(msg, appKeys.Up):
+				m.moveGraphCursor(-1)
+			case key.Matches(msg, appKeys.Down):
+				m.moveGraphCursor(1)
+			case key.Matches(msg, appKeys.PageUp):
+				m.moveGraphCursor(-m.innerHeight())
+			case key.Matches(msg, appKeys.PageDown):
+				m.moveGraphCursor(m.innerHeight())
+			case key.Matches(msg, appKeys.Top):
+				m.moveGraphCursor(-len(m.graphRows))
+			case key.Matches(msg, appKeys.Bottom):
+				m.moveGraphCursor(len(m.graphRows))
+			}
+			return m, nil
 		}
 
 	case stackLoadedMsg:
+		if msg.stack.ID != m.stack.ID {
+			return m, nil
+		}
 		m.loadingStack = false
 		m.fullStack = &msg.stack
 
 	case resourcesLoadedMsg:
+		if msg.stackID != m.stack.ID {
+			return m, nil
+		}
 		m.loadingResources = false
 		m.resourcesLoaded = true
 		m.resources = msg.resources
-		rows := make([]table.Row, len(msg.resources))
-		for i, r := range msg.resources {
-			rows[i] = table.Row{r.Name, r.Type, r.ID}
+		m.setResourceRows()
+		m.setGraph()
+		if m.pendingResourceCursor > 0 {
+			m.resourceTable.SetCursor(m.pendingResourceCursor)
+			m.pendingResourceCursor = 0
 		}
-		m.resourceTable.SetRows(rows)
 
 	case operationsLoadedMsg:
+		if msg.stackID != m.stack.ID {
+			return m, nil
+		}
+		if msg.status == "" {
+			m.loadingHistory = false
+			m.historyLoaded = true
+			m.history = msg.operations
+			m.statsViewport.SetContent(m.formatStats())
+		}
+		if msg.status != opStatusChips[m.statusChip].status {
+			return m, nil // superseded by another status chip, or for Stats
+		}
 		m.loadingOperations = false
 		m.operationsLoaded = true
 		m.operations = msg.operations
-		rows := make([]table.Row, len(msg.operations))
-		for i, op := range msg.operations {
-			rows[i] = table.Row{
-				m.styles.statusIndicator(op.Status),
-				op.ID,
-				op.Status,
-				op.CreatedAt.Format("2006-01-02 15:04"),
-			}
+		m.setOperationRows()
+		if m.pendingOperationCursor > 0 {
+			m.operationTable.SetCursor(m.pendingOperationCursor)
+			m.pendingOperationCursor = 0
 		}
-		m.operationTable.SetRows(rows)
 
 	case logsLoadedMsg:
+		if msg.stackID != m.stack.ID {
+			return m, nil
+		}
 		m.loadingLogs = false
 		m.logsLoaded = true
 		m.logs = msg.logs
@@ -208,6 +312,7 @@ func (m stackDetailModel) Update(msg tea.Msg) (stackDetailModel, tea.Cmd) {
 		m.loadingStack = false
 		m.loadingResources = false
 		m.loadingOperations = false
+		m.loadingHistory = false
 		m.loadingLogs = false
 
 	case spinner.TickMsg:
@@ -226,6 +331,8 @@ func (m stackDetailModel) Update(msg tea.Msg) (stackDetailModel, tea.Cmd) {
 		m.operationTable, cmd = m.operationTable.Update(msg)
 	case tabLogs:
 		m.logViewport, cmd = m.logViewport.Update(msg)
+	case tabStats:
+		m.statsViewport, cmd = m.statsViewport.Update(msg)
 	}
 	return m, cmd
 }
@@ -247,16 +354,20 @@ func (m stackDetailModel) View() string {
 				inner = m.spinner.View() + " Loading resources…"
 			} else if len(m.resources) == 0 {
 				inner = s.muted.Render("No resources.")
+			} else if len(m.shownResources) == 0 {
+				inner = m.renderToolbar() + "\n" + s.muted.Render("No matching resources.")
 			} else {
-				inner = m.resourceTable.View()
+				inner = m.renderToolbar() + "\n" + m.resourceTable.View()
 			}
 		case tabOperations:
 			if m.loadingOperations {
 				inner = m.spinner.View() + " Loading operations…"
-			} else if len(m.operations) == 0 {
-				inner = s.muted.Render("No operations.")
+			} else if len(m.shownOperations) == 0 {
+				inner = m.renderToolbar() + "\n" + s.muted.Render("No matching operations.")
+			} else if m.opTimeline {
+				inner = m.renderToolbar() + "\n" + m.renderOperationTimeline()
 			} else {
-				inner = m.operationTable.View()
+				inner = m.renderToolbar() + "\n" + m.operationTable.View()
 			}
 		case tabLogs:
 			if m.loadingLogs {
@@ -266,6 +377,22 @@ func (m stackDetailModel) View() string {
 			} else {
 				inner = m.logViewport.View()
 			}
+		case tabStats:
+			if m.loadingHistory {
+				inner = m.spinner.View() + " Loading operations…"
+			} else if len(m.history) == 0 {
+				inner = s.muted.Render("No operations.")
+			} else {
+				inner = m.statsViewport.View()
+			}
+		case tabGraph:
+			if m.loadingResources {
+				inner = m.spinner.View() + " Loading resources…"
+			} else if len(m.resources) == 0 {
+				inner = s.muted.Render("No resources.")
+			} else {
+				inner = m.renderGraph()
+			}
 		}
 	}
 
@@ -275,6 +402,54 @@ func (m stackDetailModel) View() string {
 	return chrome + "\n" + inner
 }
 
+// renderOperationTimeline places each operation on an axis spanning the
+// stack's history, in table order so the table cursor selects a row.
+func (m stackDetailModel) renderOperationTimeline() string {
+	s := m.styles
+	now := time.Now()
+	ops := m.shownOperations
+	from, to := ops[0].CreatedAt, ops[0].CreatedAt
+	bars := make([]timelineBar, len(ops))
+	for i, op := range ops {
+		end := op.CreatedAt.Add(opDuration(op, now))
+		if op.CreatedAt.Before(from) {
+			from = op.CreatedAt
+		}
+		if end.After(to) {
+			to = end
+		}
+		label := op.CreatedAt.Format("2006-01-02 15:04")
+		if slices.Contains(m.marked, op.ID) {
+			label += " ✓"
+		}
+		bars[i] = timelineBar{
+			label: label,
+			start: op.CreatedAt,
+			end:   end,
+			style: s.statusStyle(op.Status),
+			note:  formatDuration(end.Sub(op.CreatedAt)),
+		}
+	}
+	lines := renderTimeline(s, bars, from, to, m.width-2, from.Format("2006-01-02 15:04"), to.Format("2006-01-02 15:04"))
+
+	cursor := m.operationTable.Cursor()
+	rows := lines[1:]
+	for i := range rows {
+		prefix := "  "
+		if i == cursor {
+			prefix = s.title.Render("▸ ")
+		}
+		rows[i] = prefix + rows[i]
+	}
+	visible := max(m.innerHeight()-2, 1) // toolbar and axis
+	start := 0
+	if cursor >= visible {
+		start = cursor - visible + 1
+	}
+	end := min(start+visible, len(rows))
+	return "  " + lines[0] + "\n" + strings.Join(rows[start:end], "\n")
+}
+
 func (m stackDetailModel) renderHeader() string {
 	s := m.styles
 	ds := m.displayStack()
@@ -331,12 +506,41 @@ func (m *stackDetailModel) SetSize(w, h int) {
 	m.width = w
 	m.height = h
 	innerH := m.innerHeight()
+	m.resourceTable.SetColumns(markSorted(resourceColumns(w), resourceSorts, m.resourceQuery))
 	m.resourceTable.SetWidth(w)
-	m.resourceTable.SetHeight(innerH)
+	m.resourceTable.SetHeight(max(innerH-1, 1))
+	m.operationTable.SetColumns(markSorted(operationColumns(w), operationSorts, m.operationQuery))
 	m.operationTable.SetWidth(w)
-	m.operationTable.SetHeight(innerH)
+	m.operationTable.SetHeight(max(innerH-1, 1))
 	m.logViewport.SetWidth(w)
 	m.logViewport.SetHeight(innerH)
+	m.statsViewport.SetWidth(w)
+	m.statsViewport.SetHeight(innerH)
+}
+
+// resourceColumns splits what the ID and Created columns leave between
+// the resource name and type.
+func resourceColumns(width int) []table.Column {
+	const fixed = 16 + 16
+	flex := max(width-fixed-2*4, 40)
+	return []table.Column{
+		{Title: "Name", Width: flex / 2},
+		{Title: "Type", Width: flex - flex/2},
+		{Title: "ID", Width: 16},
+		{Title: "Created", Width: 16},
+	}
+}
+
+// operationColumns gives the operation ID what the fixed-width columns
+// leave.
+func operationColumns(width int) []table.Column {
+	const fixed = 3 + 14 + 20
+	return []table.Column{
+		{Title: " ", Width: 3},
+		{Title: "ID", Width: max(width-fixed-2*4, 16)},
+		{Title: "Status", Width: 14},
+		{Title: "Created", Width: 20},
+	}
 }
 
 func (m stackDetailModel) updateFocus() stackDetailModel {
@@ -355,7 +559,7 @@ func (m stackDetailModel) updateFocus() stackDetailModel {
 // Pointer receiver so the loading flag is visible to the caller's m.
 func (m *stackDetailModel) ensureTabLoaded() tea.Cmd {
 	switch m.activeTab {
-	case tabResources:
+	case tabResources, tabGraph:
 		if !m.resourcesLoaded {
 			m.loadingResources = true
 			return tea.Batch(m.spinner.Tick, m.fetchResources())
@@ -365,6 +569,11 @@ func (m *stackDetailModel) ensureTabLoaded() tea.Cmd {
 			m.loadingOperations = true
 			return tea.Batch(m.spinner.Tick, m.fetchOperations())
 		}
+	case tabStats:
+		if !m.historyLoaded {
+			m.loadingHistory = true
+			return tea.Batch(m.spinner.Tick, m.fetchHistory())
+		}
 	case tabLogs:
 		if !m.logsLoaded {
 			m.loadingLogs = true
@@ -376,12 +585,15 @@ func (m *stackDetailModel) ensureTabLoaded() tea.Cmd {
 
 func (m *stackDetailModel) refreshTab() tea.Cmd {
 	switch m.activeTab {
-	case tabResources:
+	case tabResources, tabGraph:
 		m.loadingResources = true
 		return tea.Batch(m.spinner.Tick, m.fetchStack(), m.fetchResources())
 	case tabOperations:
 		m.loadingOperations = true
 		return tea.Batch(m.spinner.Tick, m.fetchStack(), m.fetchOperations())
+	case tabStats:
+		m.loadingHistory = true
+		return tea.Batch(m.spinner.Tick, m.fetchStack(), m.fetchHistory())
 	case tabLogs:
 		m.loadingLogs = true
 		return tea.Batch(m.spinner.Tick, m.fetchStack(), m.fetchLogs())
@@ -389,24 +601,75 @@ func (m *stackDetailModel) refreshTab() tea.Cmd {
 	return nil
 }
 
+// restoreView reopens tab with the given table cursors and returns the
+// command that loads the tab if it is not the default one.
+func (m *stackDetailModel) restoreView(tab detailTab, resourceCursor, operationCursor int) tea.Cmd {
+	m.activeTab = tab
+	m.pendingResourceCursor = resourceCursor
+	m.pendingOperationCursor = operationCursor
+	*m = m.updateFocus()
+	if tab == tabResources {
+		return nil // Init already fetches resources
+	}
+	return m.ensureTabLoaded()
+}
+
 func (m stackDetailModel) selectedResource() (api.Resource, bool) {
 	idx := m.resourceTable.Cursor()
-	if idx < 0 || idx >= len(m.resources) {
+	if idx < 0 || idx >= len(m.resourceRows) || m.resourceRows[idx] < 0 {
 		return api.Resource{}, false
 	}
-	return m.resources[idx], true
+	return m.shownResources[m.resourceRows[idx]], true
 }
 
 func (m stackDetailModel) selectedOperation() (api.Operation, bool) {
 	idx := m.operationTable.Cursor()
-	if idx < 0 || idx >= len(m.operations) {
+	if idx < 0 || idx >= len(m.shownOperations) {
 		return api.Operation{}, false
 	}
-	return m.operations[idx], true
+	return m.shownOperations[idx], true
+}
+
+// toggleMark marks or unmarks the selected operation. Marking a third
+// operation drops the earliest mark.
+func (m *stackDetailModel) toggleMark() {
+	op, ok := m.selectedOperation()
+	if !ok {
+		return
+	}
+	if i := slices.Index(m.marked, op.ID); i >= 0 {
+		m.marked = slices.Delete(m.marked, i, i+1)
+	} else {
+		m.marked = append(m.marked, op.ID)
+		if len(m.marked) > 2 {
+			m.marked = m.marked[1:]
+		}
+	}
+	m.setOperationRows()
+}
+
+// markedOperations returns the two marked operations, if two are marked.
+func (m stackDetailModel) markedOperations() (api.Operation, api.Operation, bool) {
+	if len(m.marked) != 2 {
+		return api.Operation{}, api.Operation{}, false
+	}
+	var found []api.Operation
+	for _, id := range m.marked {
+		for _, op := range m.operations {
+			if op.ID == id {
+				found = append(found, op)
+				break
+			}
+		}
+	}
+	if len(found) != 2 {
+		return api.Operation{}, api.Operation{}, false
+	}
+	return found[0], found[1], true
 }
 
 func (m stackDetailModel) isLoading() bool {
-	return m.loadingStack || m.loadingResources || m.loadingOperations || m.loadingLogs
+	return m.loadingStack || m.loadingResources || m.loadingOperations || m.loadingHistory || m.loadingLogs
 }
 
 func (m stackDetailModel) fetchStack() tea.Cmd {
@@ -425,17 +688,27 @@ func (m stackDetailModel) fetchResources() tea.Cmd {
 		if err != nil {
 			return apiErrMsg{err: err}
 		}
-		return resourcesLoadedMsg{resources: resources}
+		return resourcesLoadedMsg{stackID: m.stack.ID, resources: resources}
 	}
 }
 
+// fetchOperations lists the operations matching the status chip.
 func (m stackDetailModel) fetchOperations() tea.Cmd {
+	return m.listOperations(opStatusChips[m.statusChip].status)
+}
+
+// fetchHistory lists every operation, for the Stats tab.
+func (m stackDetailModel) fetchHistory() tea.Cmd {
+	return m.listOperations("")
+}
+
+func (m stackDetailModel) listOperations(status string) tea.Cmd {
 	return func() tea.Msg {
-		ops, err := m.client.ListOperations(m.stack.ID, api.ListOperationsOpts{})
+		ops, err := m.client.ListOperations(m.stack.ID, api.ListOperationsOpts{Status: status})
 		if err != nil {
 			return apiErrMsg{err: err}
 		}
-		return operationsLoadedMsg{operations: ops}
+		return operationsLoadedMsg{stackID: m.stack.ID, operations: ops, status: status}
 	}
 }
 
@@ -445,7 +718,7 @@ func (m stackDetailModel) fetchLogs() tea.Cmd {
 		if err != nil {
 			return apiErrMsg{err: err}
 		}
-		return logsLoadedMsg{logs: logs}
+		return logsLoadedMsg{stackID: m.stack.ID, logs: logs}
 	}
 }
 
@@ -3,12 +3,15 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"charm.land/bubbles/v2/list"
 	"charm.land/bubbles/v2/spinner"
+	"charm.land/bubbles/v2/table"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/sanity-labs/blueprints-tui/internal/api"
+	"github.com/sanity-labs/blueprints-tui/internal/config"
 )
 
 type stackItem struct {
@@ -39,10 +42,34 @@ func (i stackItem) FilterValue() string {
 	return i.stack.Name + " " + i.stack.ID + " " + i.stack.BlueprintID
 }
 
+// stacksLoadedMsg and projectStacksLoadedMsg carry the client of the
+// stack list that asked for them, so that a list opened for another scope
+// since can tell them apart from its own.
 type stacksLoadedMsg struct {
+	client *api.Client
 	stacks []api.Stack
 }
 
+type projectStacksLoadedMsg struct {
+	client   *api.Client
+	projects []projectStacks
+}
+
+// stackRow backs one row of the all-projects table. Rows for projects
+// that failed to load have no stack.
+type stackRow struct {
+	stack   *api.Stack
+	client  *api.Client
+	project string
+}
+
+// groupRow backs one row of the grouped table: a blueprint heading, with
+// no stack, or one of the stacks under it.
+type groupRow struct {
+	group stackGroup
+	stack *stackRef
+}
+
 type stackListModel struct {
 	list    list.Model
 	client  *api.Client
@@ -52,11 +79,38 @@ type stackListModel struct {
 	spinner spinner.Model
 	err     error
 	height  int
+
+	// Restored filter and selection, applied once the first load arrives.
+	pendingFilter string
+	pendingSelect string
+
+	// allProjects lists the stacks of every project in the organization
+	// in a table instead of the organization's own stacks.
+	allProjects bool
+	table       table.Model
+	rows        []stackRow
+	width       int
+
+	// grouped shows the stacks under their blueprint, in either mode.
+	grouped    bool
+	groupTable table.Model
+	groupRows  []groupRow
+
+	// tableLayout shows the stacks in stackTable, with the configured
+	// columns, instead of the list. It does not apply to all projects.
+	tableLayout bool
+	columns     []string
+	stackTable  table.Model
+	tableStacks []api.Stack
+	// sortCol indexes columns, or is -1 for the order the API returned.
+	sortCol  int
+	sortDesc bool
 }
 
-func newStackListModel(client *api.Client, s styles) stackListModel {
+func newStackListModel(client *api.Client, s styles, cfg config.StackList) stackListModel {
 	delegate := list.NewDefaultDelegate()
 	l := list.New(nil, delegate, 0, 0)
+	l.KeyMap = listKeys()
 	l.SetShowTitle(false)
 	l.SetShowStatusBar(true)
 	l.SetFilteringEnabled(true)
@@ -67,12 +121,25 @@ func newStackListModel(client *api.Client, s styles) stackListModel {
 	sp := spinner.New()
 	sp.Spinner = spinner.Dot
 
+	t := table.New(table.WithFocused(true), table.WithKeyMap(tableKeys()))
+	t.SetStyles(s.table)
+	gt := table.New(table.WithFocused(true), table.WithKeyMap(tableKeys()))
+	gt.SetStyles(s.table)
+	st := table.New(table.WithFocused(true), table.WithKeyMap(tableKeys()))
+	st.SetStyles(s.table)
+
 	return stackListModel{
-		list:    l,
-		client:  client,
-		styles:  s,
-		loading: true,
-		spinner: sp,
+		list:        l,
+		client:      client,
+		styles:      s,
+		loading:     true,
+		spinner:     sp,
+		table:       t,
+		groupTable:  gt,
+		tableLayout: cfg.Layout == "table",
+		columns:     stackListColumns(cfg),
+		stackTable:  st,
+		sortCol:     -1,
 	}
 }
 
@@ -80,9 +147,128 @@ func (m stackListModel) Init() tea.Cmd {
 	return tea.Batch(m.spinner.Tick, m.fetchStacks())
 }
 
+// canShowAllProjects reports whether the scope is an organization, the
+// only scope with projects to fan out to.
+func (m stackListModel) canShowAllProjects() bool {
+	scopeType, _ := m.client.Scope()
+	return scopeType == "organization"
+}
+
+// toggleAllProjects switches between the organization's own stacks and
+// the stacks of all its projects, reloading the list.
+func (m stackListModel) toggleAllProjects() (stackListModel, tea.Cmd) {
+	if !m.canShowAllProjects() {
+		return m, nil
+	}
+	m.allProjects = !m.allProjects
+	return m.Refresh()
+}
+
+// toggleGrouped switches between the stacks and the stacks grouped by
+// blueprint. Grouping covers what the list shows, so a filter applied to
+// the list narrows the groups too.
+func (m stackListModel) toggleGrouped() stackListModel {
+	m.grouped = !m.grouped
+	if m.grouped {
+		m.setGroupRows()
+	}
+	return m
+}
+
+// shownStacks returns the stacks the current mode lists, with the client
+// each lives under.
+func (m stackListModel) shownStacks() []stackRef {
+	var refs []stackRef
+	if m.allProjects {
+		for _, r := range m.rows {
+			if r.stack != nil {
+				refs = append(refs, stackRef{stack: *r.stack, client: r.client, project: r.project})
+			}
+		}
+		return refs
+	}
+	for _, item := range m.list.VisibleItems() {
+		if si, ok := item.(stackItem); ok {
+			refs = append(refs, stackRef{stack: si.stack, client: m.client})
+		}
+	}
+	return refs
+}
+
+// setGroupRows fills the grouped table: each blueprint with the aggregate
+// status of its stacks, followed by the stacks themselves.
+func (m *stackListModel) setGroupRows() {
+	now := time.Now()
+	m.groupRows = nil
+	var rows []table.Row
+	for _, g := range groupByBlueprint(m.shownStacks()) {
+		status, summary := groupStatus(g.stacks)
+		m.groupRows = append(m.groupRows, groupRow{group: g})
+		rows = append(rows, table.Row{
+			m.styles.statusIndicator(status),
+			g.label(),
+			countNoun(len(g.stacks), "stack", "stacks"),
+			"",
+			summary,
+		})
+		for i := range g.stacks {
+			ref := g.stacks[i]
+			m.groupRows = append(m.groupRows, groupRow{group: g, stack: &ref})
+			indicator := m.styles.statusIndicator("")
+			if op := ref.stack.RecentOperation; op != nil {
+				indicator = m.styles.statusIndicator(op.Status)
+			}
+			name := ref.stack.Name
+			if ref.project != "" {
+				name = ref.project + " / " + name
+			}
+			resources := ""
+			if n := ref.stack.DisplayResourceCount(); n != nil {
+				resources = fmt.Sprint(*n)
+			}
+			rows = append(rows, table.Row{indicator, "  " + name, ref.stack.ID, resources, lastOperation(ref.stack, now)})
+		}
+	}
+	m.groupTable.SetRows(rows)
+	m.groupTable.SetCursor(0)
+}
+
+// groupColumns gives the blueprint and stack names what is left after the
+// fixed-width columns.
+func groupColumns(width int) []table.Column {
+	const fixed = 3 + 16 + 10
+	flex := max(width-fixed-2*5, 30)
+	return []table.Column{
+		{Title: " ", Width: 3},
+		{Title: "Blueprint / stack", Width: flex / 2},
+		{Title: "ID", Width: 16},
+		{Title: "Resources", Width: 10},
+		{Title: "Status", Width: flex - flex/2},
+	}
+}
+
+// selectedBlueprint returns the blueprint whose heading is selected in
+// the grouped table.
+func (m stackListModel) selectedBlueprint() (string, bool) {
+	idx := m.groupTable.Cursor()
+	if !m.grouped || idx < 0 || idx >= len(m.groupRows) {
+		return "", false
+	}
+	row := m.groupRows[idx]
+	if row.stack != nil || row.group.blueprintID == "" {
+		return "", false
+	}
+	return row.group.blueprintID, true
+}
+
 func (m stackListModel) Update(msg tea.Msg) (stackListModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case stacksLoadedMsg:
+		// A load for the other mode, still in flight when the user
+		// toggled all projects, must not end this one's spinner.
+		if msg.client != m.client || m.allProjects {
+			return m, nil
+		}
 		m.loading = false
 		m.err = nil
 		m.stacks = msg.stacks
@@ -91,8 +277,32 @@ func (m stackListModel) Update(msg tea.Msg) (stackListModel, tea.Cmd) {
 			items[i] = stackItem{stack: s, styles: &m.styles}
 		}
 		cmd := m.list.SetItems(items)
+		if m.pendingFilter != "" {
+			m.list.SetFilterText(m.pendingFilter)
+			m.pendingFilter = ""
+		}
+		if m.grouped {
+			m.setGroupRows()
+		}
+		if m.tableLayout {
+			m.setStackTableRows()
+		}
+		m.applyPendingSelect()
 		return m, cmd
 
+	case projectStacksLoadedMsg:
+		if msg.client != m.client || !m.allProjects {
+			return m, nil
+		}
+		m.loading = false
+		m.err = nil
+		m.setProjectRows(msg.projects)
+		if m.grouped {
+			m.setGroupRows()
+		}
+		m.applyPendingSelect()
+		return m, nil
+
 	case apiErrMsg:
 		m.loading = false
 		m.err = msg.err
@@ -107,46 +317,237 @@ func (m stackListModel) Update(msg tea.Msg) (stackListModel, tea.Cmd) {
 
 	if !m.loading {
 		var cmd tea.Cmd
-		m.list, cmd = m.list.Update(msg)
+		switch {
+		case m.grouped:
+			m.groupTable, cmd = m.groupTable.Update(msg)
+		case m.allProjects:
+			m.table, cmd = m.table.Update(msg)
+		case m.tableLayout:
+			m.stackTable, cmd = m.stackTable.Update(msg)
+		default:
+			m.list, cmd = m.list.Update(msg)
+		}
 		return m, cmd
 	}
 
 	return m, nil
 }
 
+// setProjectRows fills the all-projects table. Projects that failed to
+// load get a single row carrying the error, so one forbidden project does
+// not hide the rest.
+func (m *stackListModel) setProjectRows(projects []projectStacks) {
+	m.rows = nil
+	var rows []table.Row
+	for _, ps := range projects {
+		name := ps.project.DisplayName
+		if ps.err != nil {
+			m.rows = append(m.rows, stackRow{client: ps.client})
+			rows = append(rows, table.Row{m.styles.statusFailed.Render("!"), name, projectErrText(ps.err), "", "", ""})
+			continue
+		}
+		for i := range ps.stacks {
+			st := ps.stacks[i]
+			m.rows = append(m.rows, stackRow{stack: &st, client: ps.client, project: name})
+			indicator := m.styles.statusIndicator("")
+			if op := st.RecentOperation; op != nil {
+				indicator = m.styles.statusIndicator(op.Status)
+			}
+			resources := ""
+			if n := st.DisplayResourceCount(); n != nil {
+				resources = fmt.Sprint(*n)
+			}
+			rows = append(rows, table.Row{indicator, name, st.Name, st.ID, resources, st.BlueprintID})
+		}
+	}
+	m.table.SetRows(rows)
+	m.table.SetCursor(0)
+}
+
+// tableColumns sizes the all-projects columns to the width, giving the
+// project and stack names what is left after the fixed-width columns.
+func tableColumns(width int) []table.Column {
+	const fixed = 3 + 16 + 10 + 16
+	flex := max(width-fixed-2*6, 20)
+	return []table.Column{
+		{Title: " ", Width: 3},
+		{Title: "Project", Width: flex * 2 / 5},
+		{Title: "Name", Width: flex - flex*2/5},
+		{Title: "ID", Width: 16},
+		{Title: "Resources", Width: 10},
+		{Title: "Blueprint", Width: 16},
+	}
+}
+
 // View returns exactly m.height lines. The list bubble renders at its
 // SetSize height; loading/error states are placed in the same box.
 func (m stackListModel) View() string {
 	if m.err != nil {
-		s := m.styles.title.Render("Error") + "\n\n" + m.err.Error() + "\n\n" + m.styles.muted.Render("Press r to retry")
+		s := m.styles.title.Render("Error") + "\n\n" + m.err.Error() + "\n\n" + m.styles.muted.Render("Press "+appKeys.Refresh.Help().Key+" to retry")
 		return lipgloss.PlaceVertical(m.height, lipgloss.Top, s)
 	}
 	if m.loading {
 		s := m.spinner.View() + " Loading stacks…"
+		if m.allProjects {
+			s = m.spinner.View() + " Loading stacks across all projects…"
+		}
 		return lipgloss.PlaceVertical(m.height, lipgloss.Top, s)
 	}
+	if m.grouped {
+		if len(m.groupRows) == 0 {
+			s := m.styles.muted.Render("No stacks found.")
+			return lipgloss.PlaceVertical(m.height, lipgloss.Top, s)
+		}
+		return lipgloss.PlaceVertical(m.height, lipgloss.Top, m.groupTable.View())
+	}
+	if m.allProjects {
+		if len(m.rows) == 0 {
+			s := m.styles.muted.Render("No projects found.")
+			return lipgloss.PlaceVertical(m.height, lipgloss.Top, s)
+		}
+		return lipgloss.PlaceVertical(m.height, lipgloss.Top, m.table.View())
+	}
 	if len(m.list.Items()) == 0 {
 		s := m.styles.muted.Render("No stacks found.")
 		return lipgloss.PlaceVertical(m.height, lipgloss.Top, s)
 	}
+	if m.tableLayout {
+		return lipgloss.PlaceVertical(m.height, lipgloss.Top, m.stackTable.View())
+	}
 	return m.list.View()
 }
 
 func (m *stackListModel) SetSize(w, h int) {
+	m.width = w
 	m.height = h
 	m.list.SetSize(w, h)
+	m.table.SetColumns(tableColumns(w))
+	m.table.SetWidth(w)
+	m.table.SetHeight(h)
+	m.groupTable.SetColumns(groupColumns(w))
+	m.groupTable.SetWidth(w)
+	m.groupTable.SetHeight(h)
+	m.stackTable.SetColumns(stackTableColumns(m.columns, w, m.sortCol, m.sortDesc))
+	m.stackTable.SetWidth(w)
+	m.stackTable.SetHeight(h)
+}
+
+// stackListView is the part of the stack list a session remembers: the
+// layout and mode, the filter and the selected stack. The stack is kept by
+// ID since its row differs between the list, table and grouped layouts.
+type stackListView struct {
+	filter      string
+	selectedID  string
+	layout      string
+	grouped     bool
+	allProjects bool
+}
+
+// view captures the layout, filter and selection for the next launch.
+func (m stackListModel) view() stackListView {
+	v := stackListView{
+		layout:      "list",
+		grouped:     m.grouped,
+		allProjects: m.allProjects,
+	}
+	if m.tableLayout {
+		v.layout = "table"
+	}
+	if m.list.FilterState() == list.FilterApplied {
+		v.filter = m.list.FilterValue()
+	}
+	if st, _, ok := m.selectedStack(); ok {
+		v.selectedID = st.ID
+	}
+	return v
+}
+
+// restoreView switches to the layout and mode of v before the first load,
+// and sets the filter and selection to apply once it arrives.
+func (m *stackListModel) restoreView(v stackListView) {
+	if v.layout != "" {
+		m.tableLayout = v.layout == "table"
+	}
+	m.grouped = v.grouped
+	m.allProjects = v.allProjects && m.canShowAllProjects()
+	m.pendingFilter = v.filter
+	m.pendingSelect = v.selectedID
+}
+
+// applyPendingSelect moves the cursor of the active layout to the
+// restored stack, if it is still listed.
+func (m *stackListModel) applyPendingSelect() {
+	id := m.pendingSelect
+	if id == "" {
+		return
+	}
+	m.pendingSelect = ""
+	switch {
+	case m.grouped:
+		for i, r := range m.groupRows {
+			if r.stack != nil && r.stack.stack.ID == id {
+				m.groupTable.SetCursor(i)
+				return
+			}
+		}
+	case m.allProjects:
+		for i, r := range m.rows {
+			if r.stack != nil && r.stack.ID == id {
+				m.table.SetCursor(i)
+				return
+			}
+		}
+	case m.tableLayout:
+		for i, st := range m.tableStacks {
+			if st.ID == id {
+				m.stackTable.SetCursor(i)
+				return
+			}
+		}
+	default:
+		for i, item := range m.list.VisibleItems() {
+			if si, ok := item.(stackItem); ok && si.stack.ID == id {
+				m.list.Select(i)
+				return
+			}
+		}
+	}
 }
 
-func (m stackListModel) selectedStack() (api.Stack, bool) {
+// selectedStack returns the selected stack and the client scoped to where
+// it lives, which is the project's in all-projects mode.
+func (m stackListModel) selectedStack() (api.Stack, *api.Client, bool) {
+	if m.grouped {
+		idx := m.groupTable.Cursor()
+		if idx < 0 || idx >= len(m.groupRows) || m.groupRows[idx].stack == nil {
+			return api.Stack{}, nil, false
+		}
+		ref := m.groupRows[idx].stack
+		return ref.stack, ref.client, true
+	}
+	if m.allProjects {
+		idx := m.table.Cursor()
+		if idx < 0 || idx >= len(m.rows) || m.rows[idx].stack == nil {
+			return api.Stack{}, nil, false
+		}
+		return *m.rows[idx].stack, m.rows[idx].client, true
+	}
+	if m.tableLayout {
+		idx := m.stackTable.Cursor()
+		if idx < 0 || idx >= len(m.tableStacks) {
+			return api.Stack{}, nil, false
+		}
+		return m.tableStacks[idx], m.client, true
+	}
 	item := m.list.SelectedItem()
 	if item == nil {
-		return api.Stack{}, false
+		return api.Stack{}, nil, false
 	}
 	si, ok := item.(stackItem)
 	if !ok {
-		return api.Stack{}, false
+		return api.Stack{}, nil, false
 	}
-	return si.stack, true
+	return si.stack, m.client, true
 }
 
 func (m stackListModel) Refresh() (stackListModel, tea.Cmd) {
@@ -156,11 +557,22 @@ func (m stackListModel) Refresh() (stackListModel, tea.Cmd) {
 }
 
 func (m stackListModel) fetchStacks() tea.Cmd {
+	client := m.client
+	if m.allProjects {
+		return func() tea.Msg {
+			_, orgID := client.Scope()
+			projects, err := listProjectStacks(client, orgID)
+			if err != nil {
+				return apiErrMsg{err: err}
+			}
+			return projectStacksLoadedMsg{client: client, projects: projects}
+		}
+	}
 	return func() tea.Msg {
-		stacks, err := m.client.ListStacks()
+		stacks, err := client.ListStacks()
 		if err != nil {
 			return apiErrMsg{err: err}
 		}
-		return stacksLoadedMsg{stacks: stacks}
+		return stacksLoadedMsg{client: client, stacks: stacks}
 	}
 }
@@ -0,0 +1,248 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/table"
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+	"github.com/sanity-labs/blueprints-tui/internal/config"
+)
+
+// stackColumn is one column of the stack table, keyed in stackColumns by
+// its name in config.StackColumns.
+type stackColumn struct {
+	title string
+	// width is the column's fixed width. Columns without one share what
+	// the fixed columns leave, in proportion to flex.
+	width int
+	flex  int
+	cell  func(st api.Stack, s styles, now time.Time) string
+	// less orders stacks ascending. Times sort newest first, so the
+	// ascending order of every column puts what changed recently on top.
+	less func(a, b api.Stack) bool
+}
+
+var stackColumns = map[string]stackColumn{
+	"name": {
+		title: "Name",
+		flex:  3,
+		cell:  func(st api.Stack, _ styles, _ time.Time) string { return st.Name },
+		less: func(a, b api.Stack) bool {
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		},
+	},
+	"id": {
+		title: "ID",
+		width: 16,
+		cell:  func(st api.Stack, _ styles, _ time.Time) string { return st.ID },
+		less:  func(a, b api.Stack) bool { return a.ID < b.ID },
+	},
+	"blueprint": {
+		title: "Blueprint",
+		flex:  2,
+		cell:  func(st api.Stack, _ styles, _ time.Time) string { return st.BlueprintID },
+		less:  func(a, b api.Stack) bool { return a.BlueprintID < b.BlueprintID },
+	},
+	"resources": {
+		title: "Resources",
+		width: 10,
+		cell: func(st api.Stack, _ styles, _ time.Time) string {
+			if n := st.DisplayResourceCount(); n != nil {
+				return fmt.Sprint(*n)
+			}
+			return ""
+		},
+		less: func(a, b api.Stack) bool { return resourceCount(a) < resourceCount(b) },
+	},
+	"status": {
+		title: "Status",
+		width: 14,
+		cell: func(st api.Stack, s styles, _ time.Time) string {
+			if op := st.RecentOperation; op != nil {
+				return s.statusIndicator(op.Status) + " " + strings.ToLower(strings.ReplaceAll(op.Status, "_", " "))
+			}
+			return s.statusIndicator("")
+		},
+		less: func(a, b api.Stack) bool { return statusRank(a) < statusRank(b) },
+	},
+	"age": {
+		title: "Last op",
+		width: 9,
+		cell: func(st api.Stack, _ styles, now time.Time) string {
+			if op := st.RecentOperation; op != nil {
+				return formatAge(op.CreatedAt, now)
+			}
+			return ""
+		},
+		less: func(a, b api.Stack) bool {
+			if a.RecentOperation == nil || b.RecentOperation == nil {
+				return b.RecentOperation == nil && a.RecentOperation != nil
+			}
+			return a.RecentOperation.CreatedAt.After(b.RecentOperation.CreatedAt)
+		},
+	},
+	"updated": {
+		title: "Updated",
+		width: 16,
+		cell: func(st api.Stack, _ styles, _ time.Time) string {
+			return st.UpdatedAt.Format("2006-01-02 15:04")
+		},
+		less: func(a, b api.Stack) bool { return a.UpdatedAt.After(b.UpdatedAt) },
+	},
+}
+
+func resourceCount(st api.Stack) int {
+	if n := st.DisplayResourceCount(); n != nil {
+		return *n
+	}
+	return -1
+}
+
+// statusRank orders stacks by their latest operation, failures first and
+// stacks never deployed last.
+func statusRank(st api.Stack) int {
+	op := st.RecentOperation
+	switch {
+	case op == nil:
+		return 4
+	case strings.EqualFold(op.Status, "FAILED"):
+		return 0
+	case isInProgress(op.Status):
+		return 1
+	case strings.EqualFold(op.Status, "COMPLETED"), strings.EqualFold(op.Status, "SUCCESS"):
+		return 2
+	default:
+		return 3
+	}
+}
+
+// stackTableColumns sizes the configured columns to the width. Fixed
+// columns keep their width and the flexible ones split the rest; the
+// sorted column's title carries the direction.
+func stackTableColumns(names []string, width, sortCol int, sortDesc bool) []table.Column {
+	fixed, flex, lastFlex := 0, 0, -1
+	for i, name := range names {
+		c := stackColumns[name]
+		fixed += c.width
+		flex += c.flex
+		if c.flex > 0 {
+			lastFlex = i
+		}
+	}
+	spare := max(width-fixed-2*len(names), 5*flex)
+
+	cols := make([]table.Column, len(names))
+	given := 0
+	for i, name := range names {
+		c := stackColumns[name]
+		w := c.width
+		switch {
+		case i == lastFlex:
+			// The last flexible column takes the rounding remainder.
+			w = spare - given
+		case c.flex > 0:
+			w = spare * c.flex / flex
+			given += w
+		}
+		title := c.title
+		if i == sortCol {
+			if sortDesc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		cols[i] = table.Column{Title: title, Width: w}
+	}
+	return cols
+}
+
+// showsTable reports whether the stack table is on screen; grouping and
+// all projects take precedence over the layout.
+func (m stackListModel) showsTable() bool {
+	return m.tableLayout && !m.grouped && !m.allProjects
+}
+
+// toggleLayout switches the stack list between the list and the table.
+func (m stackListModel) toggleLayout() stackListModel {
+	m.tableLayout = !m.tableLayout
+	if m.tableLayout {
+		m.setStackTableRows()
+	}
+	return m
+}
+
+// cycleSort sorts the table by the next column, in ascending order, and
+// after the last column goes back to the order the API returned.
+func (m stackListModel) cycleSort() stackListModel {
+	m.sortCol++
+	if m.sortCol >= len(m.columns) {
+		m.sortCol = -1
+	}
+	m.sortDesc = false
+	m.setStackTableRows()
+	return m
+}
+
+// reverseSort flips the sort direction of the table.
+func (m stackListModel) reverseSort() stackListModel {
+	if m.sortCol < 0 {
+		m.sortCol = 0
+	}
+	m.sortDesc = !m.sortDesc
+	m.setStackTableRows()
+	return m
+}
+
+// setStackTableRows fills the table from the stacks the list shows, so a
+// filter applied to the list carries over, keeping the selected stack
+// selected across a re-sort.
+func (m *stackListModel) setStackTableRows() {
+	selected := ""
+	if idx := m.stackTable.Cursor(); idx >= 0 && idx < len(m.tableStacks) {
+		selected = m.tableStacks[idx].ID
+	}
+
+	m.tableStacks = nil
+	for _, ref := range m.shownStacks() {
+		m.tableStacks = append(m.tableStacks, ref.stack)
+	}
+	if m.sortCol >= 0 {
+		less := stackColumns[m.columns[m.sortCol]].less
+		sort.SliceStable(m.tableStacks, func(i, j int) bool {
+			if m.sortDesc {
+				return less(m.tableStacks[j], m.tableStacks[i])
+			}
+			return less(m.tableStacks[i], m.tableStacks[j])
+		})
+	}
+
+	now := time.Now()
+	cursor := 0
+	rows := make([]table.Row, len(m.tableStacks))
+	for i, st := range m.tableStacks {
+		row := make(table.Row, len(m.columns))
+		for j, name := range m.columns {
+			row[j] = stackColumns[name].cell(st, m.styles, now)
+		}
+		rows[i] = row
+		if st.ID == selected {
+			cursor = i
+		}
+	}
+	m.stackTable.SetColumns(stackTableColumns(m.columns, m.width, m.sortCol, m.sortDesc))
+	m.stackTable.SetRows(rows)
+	m.stackTable.SetCursor(cursor)
+}
+
+// stackListColumns returns the configured table columns, defaulting to
+// all of them.
+func stackListColumns(cfg config.StackList) []string {
+	if len(cfg.Columns) == 0 {
+		return config.StackColumns
+	}
+	return cfg.Columns
+}
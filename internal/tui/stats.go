@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+)
+
+const (
+	// statsDays is how many days the deploys-per-day sparkline covers.
+	statsDays = 30
+	// statsRecent is how many of the newest operations are searched for
+	// the longest ones.
+	statsRecent = 20
+	// statsLongest is how many of the longest recent operations are listed.
+	statsLongest = 5
+)
+
+// percentile returns the p-th percentile (0–100) of sorted durations
+// using the nearest-rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank-1, 0), len(sorted)-1)]
+}
+
+// opsPerDay counts operations created on each of the last days days,
+// oldest first, with today last.
+func opsPerDay(ops []api.Operation, days int, now time.Time) []int {
+	counts := make([]int, days)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, op := range ops {
+		t := op.CreatedAt.In(now.Location())
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location())
+		ago := int(today.Sub(day).Hours() / 24)
+		if ago >= 0 && ago < days {
+			counts[days-1-ago]++
+		}
+	}
+	return counts
+}
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders counts as block characters scaled to the largest.
+// Zero renders as a dot so quiet days read as gaps on a baseline.
+func sparkline(counts []int) string {
+	peak := 0
+	for _, c := range counts {
+		peak = max(peak, c)
+	}
+	var b strings.Builder
+	for _, c := range counts {
+		if c == 0 || peak == 0 {
+			b.WriteRune('·')
+			continue
+		}
+		i := (c*len(sparkBlocks) - 1) / peak
+		b.WriteRune(sparkBlocks[min(i, len(sparkBlocks)-1)])
+	}
+	return b.String()
+}
+
+// formatStats renders the Stats tab from the stack's operation history.
+func (m stackDetailModel) formatStats() string {
+	s := m.styles
+	ops := m.history
+	now := time.Now()
+
+	var completed, failed, running int
+	var durations []time.Duration
+	for _, op := range ops {
+		switch {
+		case isInProgress(op.Status):
+			running++
+		case strings.EqualFold(op.Status, "FAILED"):
+			failed++
+		case strings.EqualFold(op.Status, "COMPLETED"), strings.EqualFold(op.Status, "SUCCESS"):
+			completed++
+		}
+		if op.CompletedAt != nil {
+			durations = append(durations, op.CompletedAt.Sub(op.CreatedAt))
+		}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	var b strings.Builder
+	pct := func(n int) string {
+		return fmt.Sprintf("%d (%.0f%%)", n, float64(n)/float64(len(ops))*100)
+	}
+
+	b.WriteString(s.sectionHead.Render("Outcomes") + "\n")
+	b.WriteString(s.headerValue.Render(countNoun(len(ops), "operation", "operations")) + "\n")
+	b.WriteString(s.statusIndicator("COMPLETED") + " completed    " + pct(completed) + "\n")
+	b.WriteString(s.statusIndicator("FAILED") + " failed       " + pct(failed) + "\n")
+	if running > 0 {
+		b.WriteString(s.statusIndicator("IN_PROGRESS") + " in progress  " + pct(running) + "\n")
+	}
+	if other := len(ops) - completed - failed - running; other > 0 {
+		b.WriteString(s.statusIndicator("") + " other        " + pct(other) + "\n")
+	}
+	if finished := completed + failed; finished > 0 {
+		b.WriteString(s.muted.Render(fmt.Sprintf("Success rate %.0f%% of finished operations",
+			float64(completed)/float64(finished)*100)) + "\n")
+	}
+
+	b.WriteString("\n" + s.sectionHead.Render("Duration") + "\n")
+	if len(durations) == 0 {
+		b.WriteString(s.muted.Render("No finished operations.") + "\n")
+	} else {
+		b.WriteString(fmt.Sprintf("p50 %s   p95 %s   max %s",
+			formatDuration(percentile(durations, 50)),
+			formatDuration(percentile(durations, 95)),
+			formatDuration(durations[len(durations)-1])) + "\n")
+	}
+
+	counts := opsPerDay(ops, statsDays, now)
+	total, peak := 0, 0
+	for _, c := range counts {
+		total += c
+		peak = max(peak, c)
+	}
+	b.WriteString("\n" + s.sectionHead.Render(fmt.Sprintf("Deploys per day (last %d days)", statsDays)) + "\n")
+	b.WriteString(s.title.Render(sparkline(counts)) + "\n")
+	first := now.AddDate(0, 0, 1-statsDays).Format("Jan 2")
+	b.WriteString(s.muted.Render(first+strings.Repeat(" ", max(statsDays-len(first)-len("today"), 1))+"today") + "\n")
+	b.WriteString(s.muted.Render(fmt.Sprintf("%s, at most %d a day",
+		countNoun(total, "operation", "operations"), peak)) + "\n")
+
+	recent := ops
+	if len(recent) > statsRecent {
+		recent = recent[:statsRecent]
+	}
+	longest := make([]api.Operation, len(recent))
+	copy(longest, recent)
+	sort.SliceStable(longest, func(i, j int) bool {
+		return opDuration(longest[i], now) > opDuration(longest[j], now)
+	})
+	if len(longest) > statsLongest {
+		longest = longest[:statsLongest]
+	}
+	b.WriteString("\n" + s.sectionHead.Render(fmt.Sprintf("Longest of the last %d", len(recent))) + "\n")
+	for _, op := range longest {
+		b.WriteString(fmt.Sprintf("%s %-16s %s  %s\n",
+			s.statusIndicator(op.Status),
+			op.ID,
+			s.muted.Render(op.CreatedAt.Format("2006-01-02 15:04")),
+			formatDuration(opDuration(op, now))))
+	}
+	return b.String()
+}
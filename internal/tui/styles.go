@@ -20,6 +20,7 @@ type styles struct {
 	tabUnderline lipgloss.Style
 	keycap       lipgloss.Style
 	help         lipgloss.Style
+	modal        lipgloss.Style
 
 	statusCompleted  lipgloss.Style
 	statusFailed     lipgloss.Style
@@ -105,6 +106,11 @@ func newStyles(isDark bool) styles {
 		Foreground(muted).
 		PaddingTop(1)
 
+	s.modal = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(accent).
+		Padding(1, 2)
+
 	// Status colors — blue for success to avoid red/green colorblindness issues
 	s.statusCompleted = lipgloss.NewStyle().Foreground(green)
 	s.statusFailed = lipgloss.NewStyle().Foreground(red)
@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"github.com/sanity-labs/blueprints-tui/internal/api"
+)
+
+// timelineBar is one row of a timeline: a labelled span drawn as a
+// horizontal bar against the shared time axis.
+type timelineBar struct {
+	label string
+	start time.Time
+	end   time.Time
+	style lipgloss.Style
+	// note follows the bar, e.g. the span's duration.
+	note string
+}
+
+const (
+	timelineLabelWidth = 20
+	timelineNoteWidth  = 9
+)
+
+// renderTimeline draws bars against the axis from..to in width columns.
+// Every bar is at least one cell wide so instant events stay visible.
+// The returned lines start with the axis.
+func renderTimeline(s styles, bars []timelineBar, from, to time.Time, width int, axisLeft, axisRight string) []string {
+	span := to.Sub(from)
+	if span <= 0 {
+		span = time.Second
+	}
+	track := max(width-timelineLabelWidth-timelineNoteWidth-4, 10)
+	pos := func(t time.Time) int {
+		p := int(float64(t.Sub(from)) / float64(span) * float64(track))
+		return min(max(p, 0), track-1)
+	}
+
+	gap := max(track-lipgloss.Width(axisLeft)-lipgloss.Width(axisRight), 1)
+	axis := strings.Repeat(" ", timelineLabelWidth+2) +
+		s.muted.Render(axisLeft+strings.Repeat(" ", gap)+axisRight)
+	lines := []string{axis}
+
+	for _, b := range bars {
+		start, end := pos(b.start), pos(b.end)
+		label := truncate(b.label, timelineLabelWidth)
+		row := fmt.Sprintf("%-*s  ", timelineLabelWidth, label) +
+			strings.Repeat(" ", start) +
+			b.style.Render(strings.Repeat("█", end-start+1)) +
+			strings.Repeat(" ", track-end-1) +
+			"  " + s.muted.Render(b.note)
+		lines = append(lines, row)
+	}
+	return lines
+}
+
+// truncate shortens s to n cells, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// resourcePhase is the span of an operation's logs about one resource.
+type resourcePhase struct {
+	resourceID string
+	start      time.Time
+	end        time.Time
+	failed     bool
+}
+
+// resourcePhases reconstructs per-resource phases from an operation's
+// logs: each phase runs from the first log about the resource to the log
+// after its last one, so a resource's final step counts until the
+// operation moved on. Logs without a ResourceID only bound the other
+// phases. Phases are ordered by start.
+func resourcePhases(logs []api.Log, end time.Time) []resourcePhase {
+	sorted := oldestFirst(logs)
+
+	byID := make(map[string]*resourcePhase)
+	var order []string
+	for i, l := range sorted {
+		if l.ResourceID == "" {
+			continue
+		}
+		next := end
+		if i+1 < len(sorted) {
+			next = sorted[i+1].Timestamp
+		}
+		p, ok := byID[l.ResourceID]
+		if !ok {
+			p = &resourcePhase{resourceID: l.ResourceID, start: l.Timestamp}
+			byID[l.ResourceID] = p
+			order = append(order, l.ResourceID)
+		}
+		if next.After(p.end) {
+			p.end = next
+		}
+		switch strings.ToUpper(l.Level) {
+		case "ERROR", "FATAL":
+			p.failed = true
+		}
+	}
+
+	phases := make([]resourcePhase, len(order))
+	for i, id := range order {
+		phases[i] = *byID[id]
+	}
+	return phases
+}
+
+// longestGap returns the longest quiet stretch between consecutive logs
+// and the log that started it, which is usually the step that stalled.
+func longestGap(logs []api.Log) (time.Duration, api.Log, bool) {
+	sorted := oldestFirst(logs)
+	var (
+		gap    time.Duration
+		before api.Log
+	)
+	for i := 1; i < len(sorted); i++ {
+		if d := sorted[i].Timestamp.Sub(sorted[i-1].Timestamp); d > gap {
+			gap, before = d, sorted[i-1]
+		}
+	}
+	return gap, before, gap > 0
+}
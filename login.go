@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sanity-labs/blueprints-tui/internal/auth"
+	"github.com/sanity-labs/blueprints-tui/internal/browser"
+	"github.com/sanity-labs/blueprints-tui/internal/config"
+)
+
+// runLogin implements `blueprints-tui login`: it runs the device
+// authorization flow against the configured API URL and saves the token.
+func runLogin(args []string) int {
+	fs := flag.NewFlagSet("login", flag.ExitOnError)
+	var flags config.Flags
+	fs.StringVar(&flags.APIURL, "api-url", "", "Blueprints API base URL")
+	fs.BoolVar(&flags.Staging, "staging", false, "use staging environment (sanity.work)")
+	store := fs.String("store", "", "where to save the token: file or keyring (default from config)")
+	noBrowser := fs.Bool("no-browser", false, "print the login URL instead of opening a browser")
+	fs.Parse(args)
+
+	cfg, err := config.Load(flags)
+	if err != nil && !errors.Is(err, config.ErrNoToken) {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	ts, err := cfg.Store(*store)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	client := auth.NewClient(cfg.APIURL)
+	ctx := context.Background()
+	code, err := client.Start(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	fmt.Printf("Open %s\nand confirm the code %s\n\n", code.BrowserURL(), code.UserCode)
+	if !*noBrowser {
+		if err := browser.Open(code.BrowserURL()); err != nil {
+			fmt.Println("Could not open a browser; open the URL above manually.")
+		}
+	}
+	fmt.Println("Waiting for authorization…")
+
+	token, err := client.Poll(ctx, code)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+	if err := ts.Store(token); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: saving token: %s\n", err)
+		return 1
+	}
+	fmt.Printf("Logged in. Token saved to %s.\n", ts.Name())
+
+	// Warn when the saved token would not be the one picked up at startup.
+	_, src, err := config.ResolveToken(cfg.Sources)
+	switch {
+	case err != nil:
+		fmt.Printf("Note: %s is not read at startup; enable it in %s.\n", ts.Name(), cfg.File.Path())
+	case src != ts.Name():
+		fmt.Printf("Note: %s takes precedence over %s and will be used instead.\n", src, ts.Name())
+	}
+	return 0
+}
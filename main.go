@@ -1,34 +1,108 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/sanity-labs/blueprints-tui/internal/api"
 	"github.com/sanity-labs/blueprints-tui/internal/config"
+	"github.com/sanity-labs/blueprints-tui/internal/deeplink"
+	"github.com/sanity-labs/blueprints-tui/internal/history"
+	"github.com/sanity-labs/blueprints-tui/internal/state"
 	"github.com/sanity-labs/blueprints-tui/internal/tui"
 )
 
 func main() {
-	token := flag.String("token", "", "Sanity API auth token")
-	org := flag.String("org", "", "Sanity organization ID")
-	project := flag.String("project", "", "Sanity project ID")
-	apiURL := flag.String("api-url", "", "Blueprints API base URL")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "login":
+			os.Exit(runLogin(os.Args[2:]))
+		case "doctor":
+			os.Exit(runDoctor(os.Args[2:]))
+		}
+	}
+
+	var flags config.Flags
+	configFlags(flag.CommandLine, &flags)
 	debug := flag.Bool("debug", false, "print debug info to stderr")
-	staging := flag.Bool("staging", false, "use staging environment (sanity.work)")
+	var target deeplink.Link
+	flag.StringVar(&target.StackID, "stack", "", "open this stack on startup")
+	flag.StringVar(&target.ResourceID, "resource", "", "open this resource on startup")
+	flag.StringVar(&target.OperationID, "operation", "", "open this operation on startup")
+	flag.Usage = usage
 	flag.Parse()
 
-	cfg, err := config.Load(*token, *org, *project, *apiURL, *staging)
+	link, err := startupLink(target, flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(flags)
 	cfg.Debug = *debug
+	opts := tui.Options{
+		HasScope: cfg.ScopeID != "",
+		ReloadToken: func() (string, string, error) {
+			return config.ResolveToken(cfg.Sources)
+		},
+	}
+	if errors.Is(err, config.ErrNoToken) {
+		// First run: log in from inside the TUI instead of bailing out.
+		opts.LoginStore, err = cfg.Store("")
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		os.Exit(1)
 	}
 
+	if link != nil && link.ScopeID != "" {
+		// A scope in the link wins over the environment, but an explicit
+		// flag pointing elsewhere is a mistake worth reporting.
+		if (flags.Org != "" || flags.Project != "") &&
+			(link.ScopeType != cfg.ScopeType || link.ScopeID != cfg.ScopeID) {
+			fmt.Fprintf(os.Stderr, "Error: link is for %s %s but %s selects %s %s\n",
+				link.ScopeType, link.ScopeID, cfg.ScopeSource, cfg.ScopeType, cfg.ScopeID)
+			os.Exit(1)
+		}
+		cfg.ScopeType, cfg.ScopeID = link.ScopeType, link.ScopeID
+		opts.HasScope = true
+	}
+	opts.Link = link
+	opts.Environments = environments(cfg)
+	opts.StackList = cfg.File.StackList
+	if err := tui.BindKeys(cfg.File.Keys); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
 	client := api.NewClient(cfg.APIURL, cfg.Token, cfg.ScopeType, cfg.ScopeID, cfg.Debug)
-	model := tui.NewModel(client, cfg.ScopeID != "")
+	if cfg.TokenSource != "" {
+		client.Debugf("token source: %s", cfg.TokenSource)
+	}
+
+	st, err := state.Load()
+	if err != nil {
+		client.Debugf("loading state: %s", err)
+	}
+	opts.State = st
+	if opts.History, err = history.Open(); err != nil {
+		client.Debugf("opening resource history: %s", err)
+	}
+	if cfg.File.ResumeScope {
+		opts.ResumeScope = st.LastScope
+	}
+	// Reopen the last view stack unless a link or a different scope was
+	// asked for.
+	if s := st.Session; s != nil && link == nil && cfg.File.RestoresSession() &&
+		(cfg.ScopeID == "" || (s.Scope.Type == cfg.ScopeType && s.Scope.ID == cfg.ScopeID)) {
+		opts.Session = s
+	}
+	model := tui.NewModel(client, opts)
 
 	p := tea.NewProgram(model)
 	if _, err := p.Run(); err != nil {
@@ -36,3 +110,72 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// environments turns the [environments.*] tables of the config file into
+// endpoints the TUI can compare stacks against. Each resolves its token
+// and builds its client once, on first use.
+func environments(cfg config.Config) []tui.Environment {
+	var envs []tui.Environment
+	for _, name := range cfg.File.EnvironmentNames() {
+		env := cfg.File.Environments[name]
+		connect := sync.OnceValues(func() (*api.Client, error) {
+			token, _, err := config.ResolveToken(env.Sources())
+			if err != nil {
+				return nil, fmt.Errorf("environment %s: %w", name, err)
+			}
+			// debug.log belongs to the main client; opening it again would
+			// truncate it.
+			c := api.NewClient(env.APIURL, token, "", "", false)
+			// Nothing prompts for a new token for this client.
+			c.DisableReauth()
+			return c, nil
+		})
+		envs = append(envs, tui.Environment{Name: name, APIURL: env.APIURL, Connect: connect})
+	}
+	return envs
+}
+
+// startupLink combines the --stack, --resource and --operation flags with
+// an optional blueprints-tui:// argument. It returns nil when neither was
+// given.
+func startupLink(flags deeplink.Link, args []string) (*deeplink.Link, error) {
+	switch {
+	case len(args) > 1:
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(args[1:], " "))
+	case len(args) == 1:
+		if !deeplink.IsLink(args[0]) {
+			return nil, fmt.Errorf("unexpected argument %q (want a %s:// link)", args[0], deeplink.Scheme)
+		}
+		if flags != (deeplink.Link{}) {
+			return nil, fmt.Errorf("use either a link or --stack/--resource/--operation, not both")
+		}
+		l, err := deeplink.Parse(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return &l, nil
+	case flags == (deeplink.Link{}):
+		return nil, nil
+	}
+	if err := flags.Validate(); err != nil {
+		return nil, err
+	}
+	return &flags, nil
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] [%s://stack/<id>[/operation/<id>]]\n", os.Args[0], deeplink.Scheme)
+	fmt.Fprintf(out, "       %s login | doctor [flags]\n\nFlags:\n", os.Args[0])
+	flag.PrintDefaults()
+}
+
+// configFlags registers the flags that feed config.Load on fs.
+func configFlags(fs *flag.FlagSet, f *config.Flags) {
+	fs.StringVar(&f.Token, "token", "", "Sanity API auth token")
+	fs.BoolVar(&f.TokenStdin, "token-stdin", false, "read the Sanity API auth token from stdin")
+	fs.StringVar(&f.Org, "org", "", "Sanity organization ID")
+	fs.StringVar(&f.Project, "project", "", "Sanity project ID")
+	fs.StringVar(&f.APIURL, "api-url", "", "Blueprints API base URL")
+	fs.BoolVar(&f.Staging, "staging", false, "use staging environment (sanity.work)")
+}